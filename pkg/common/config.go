@@ -0,0 +1,179 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadConfig populates the struct pointed by target from environment variables described with struct tags:
+//
+//	MongoUrl string        `env:"MONGO_URL,required"`
+//	Timeout  time.Duration `env:"MONGO_CONNECTION_TIMEOUT" default:"10s"`
+//	Hosts    []string      `env:"HOSTS" default:"a,b"`
+//
+// Supported field types are string, bool, all int and uint kinds, float64, time.Duration (parsed with
+// ParseDuration), slices of those (comma separated) and nested structs. Every missing or invalid
+// variable is collected and reported at once in a *ConfigError.
+func LoadConfig(target interface{}) error {
+	return LoadConfigWithLookup(target, os.LookupEnv)
+}
+
+// LoadConfigWithLookup is LoadConfig reading variables through lookup instead of the process environment
+func LoadConfigWithLookup(target interface{}, lookup func(key string) (string, bool)) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config target must be a non nil pointer to struct, got %T", target)
+	}
+	configError := &ConfigError{}
+	loadStruct(value.Elem(), lookup, configError)
+	if len(configError.Problems) > 0 {
+		return configError
+	}
+	return nil
+}
+
+// ConfigError lists every environment variable which is missing or has an invalid value
+type ConfigError struct {
+	Problems []ConfigProblem
+}
+
+type ConfigProblem struct {
+	Variable string
+	Reason   string
+}
+
+func (e *ConfigError) Error() string {
+	problems := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		problems = append(problems, fmt.Sprintf("%s: %s", problem.Variable, problem.Reason))
+	}
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+func (e *ConfigError) add(variable string, reason string) {
+	e.Problems = append(e.Problems, ConfigProblem{Variable: variable, Reason: reason})
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func loadStruct(value reflect.Value, lookup func(string) (string, bool), configError *ConfigError) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, hasTag := field.Tag.Lookup("env")
+		if !hasTag {
+			if field.Type.Kind() == reflect.Struct {
+				loadStruct(value.Field(i), lookup, configError)
+			}
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		required := false
+		for _, option := range strings.Split(options, ",") {
+			if strings.TrimSpace(option) == "required" {
+				required = true
+			}
+		}
+
+		rawValue, found := lookup(name)
+		if !found || rawValue == "" {
+			defaultValue, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				if required {
+					configError.add(name, "required variable is not set")
+				}
+				continue
+			}
+			rawValue = defaultValue
+		}
+
+		if err := setFieldValue(value.Field(i), rawValue); err != nil {
+			configError.add(name, err.Error())
+		}
+	}
+}
+
+// ParseDuration parses a duration in time.ParseDuration format, e.g. 10s. A bare integer is read as
+// nanoseconds, the format durations were configured in before.
+func ParseDuration(rawValue string) (time.Duration, error) {
+	duration, err := time.ParseDuration(rawValue)
+	if err == nil {
+		return duration, nil
+	}
+	if nanoseconds, err := strconv.ParseInt(rawValue, 10, 64); err == nil {
+		return time.Duration(nanoseconds), nil
+	}
+	return 0, fmt.Errorf("invalid duration '%s', expected e.g. 500ms, 10s or 1m", rawValue)
+}
+
+func setFieldValue(field reflect.Value, rawValue string) error {
+	if field.Kind() == reflect.Slice {
+		parts := strings.Split(rawValue, ",")
+		slice := reflect.MakeSlice(field.Type(), 0, len(parts))
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err := setScalarValue(element, part); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, element)
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalarValue(field, strings.TrimSpace(rawValue))
+}
+
+func setScalarValue(field reflect.Value, rawValue string) error {
+	if field.Type() == durationType {
+		duration, err := ParseDuration(rawValue)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(rawValue)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(rawValue)
+		if err != nil {
+			return fmt.Errorf("invalid boolean '%s'", rawValue)
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(rawValue, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer '%s'", rawValue)
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(rawValue, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer '%s'", rawValue)
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(rawValue, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number '%s'", rawValue)
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Url      string        `env:"URL,required"`
+	Timeout  time.Duration `env:"TIMEOUT" default:"10s"`
+	Enabled  bool          `env:"ENABLED" default:"true"`
+	Size     int           `env:"SIZE" default:"5"`
+	Ratio    float64       `env:"RATIO"`
+	Hosts    []string      `env:"HOSTS" default:"a,b"`
+	Nested   testNestedConfig
+	internal string
+}
+
+type testNestedConfig struct {
+	Port uint16 `env:"PORT" default:"8080"`
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		want         testConfig
+		wantProblems []string
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"URL": "mongodb://host"},
+			want: testConfig{Url: "mongodb://host", Timeout: 10 * time.Second, Enabled: true, Size: 5, Hosts: []string{"a", "b"}, Nested: testNestedConfig{Port: 8080}},
+		},
+		{
+			name: "values",
+			env:  map[string]string{"URL": "u", "TIMEOUT": "500ms", "ENABLED": "false", "SIZE": "7", "RATIO": "0.5", "HOSTS": " x, ,y ", "PORT": "1"},
+			want: testConfig{Url: "u", Timeout: 500 * time.Millisecond, Size: 7, Ratio: 0.5, Hosts: []string{"x", "y"}, Nested: testNestedConfig{Port: 1}},
+		},
+		{
+			name: "duration in nanoseconds",
+			env:  map[string]string{"URL": "u", "TIMEOUT": "5000000000"},
+			want: testConfig{Url: "u", Timeout: 5 * time.Second, Enabled: true, Size: 5, Hosts: []string{"a", "b"}, Nested: testNestedConfig{Port: 8080}},
+		},
+		{
+			name:         "all problems at once",
+			env:          map[string]string{"TIMEOUT": "soon", "SIZE": "x", "PORT": "70000"},
+			wantProblems: []string{"URL", "TIMEOUT", "SIZE", "PORT"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var config testConfig
+			err := LoadConfigWithLookup(&config, func(key string) (string, bool) {
+				value, ok := test.env[key]
+				return value, ok
+			})
+			if test.wantProblems != nil {
+				var configError *ConfigError
+				if !errors.As(err, &configError) {
+					t.Fatalf("LoadConfigWithLookup() error = %v, want *ConfigError", err)
+				}
+				var variables []string
+				for _, problem := range configError.Problems {
+					variables = append(variables, problem.Variable)
+				}
+				if !reflect.DeepEqual(variables, test.wantProblems) {
+					t.Errorf("problems of %v, want %v", variables, test.wantProblems)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfigWithLookup() error = %v", err)
+			}
+			if !reflect.DeepEqual(config, test.want) {
+				t.Errorf("LoadConfigWithLookup() = %+v, want %+v", config, test.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"unset", "", time.Minute},
+		{"duration", "10s", 10 * time.Second},
+		{"nanoseconds", "5000000000", 5 * time.Second},
+		{"invalid", "soon", time.Minute},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("TEST_DURATION", test.value)
+			if got := GetEnvDuration("TEST_DURATION", time.Minute); got != test.want {
+				t.Errorf("GetEnvDuration() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IdField is the storage field used as the unique tie-breaker of keyset pagination
+const IdField = "_id"
+
+// CursorKey is the value of a sort field of the item a page starts or ends with
+type CursorKey struct {
+	Field string
+	Value interface{}
+}
+
+// Cursor is the decoded continuation token of keyset pagination. Keys hold the sort field values of
+// the boundary item followed by its IdField, Backward tells whether items before the boundary are requested.
+type Cursor struct {
+	Sort     []SortOrder `json:"s"`
+	Keys     []CursorKey `json:"k"`
+	Backward bool        `json:"b,omitempty"`
+}
+
+// CursorPageFilter selects a page of keyset pagination. Cursor is nil for the first page.
+type CursorPageFilter struct {
+	PageSize int64 `validate:"min=1"`
+	Sort     []SortOrder
+	Cursor   *Cursor
+}
+
+// SortWithTieBreaker returns the sort orders followed by IdField in the direction of the last sort order
+func (f CursorPageFilter) SortWithTieBreaker() []SortOrder {
+	sortOrders := make([]SortOrder, 0, len(f.Sort)+1)
+	tieBreakerType := SortAsc
+	for _, sortOrder := range f.Sort {
+		if sortOrder.Field == IdField {
+			return append(sortOrders, sortOrder)
+		}
+		sortOrders = append(sortOrders, sortOrder)
+		tieBreakerType = sortOrder.Type
+	}
+	return append(sortOrders, SortOrder{Field: IdField, Type: tieBreakerType})
+}
+
+// IsBackward reports whether the page before the cursor is requested
+func (f CursorPageFilter) IsBackward() bool {
+	return f.Cursor != nil && f.Cursor.Backward
+}
+
+type CursorPaginated[D any] struct {
+	Data       []*D                  `json:"data"`
+	Pagination *CursorPaginationData `json:"page"`
+	// NextCursor and PrevCursor are encoded into Pagination by EncodeCursorPage
+	NextCursor *Cursor `json:"-"`
+	PrevCursor *Cursor `json:"-"`
+}
+
+type CursorPaginationData struct {
+	PageSize int64  `json:"pageSize"`
+	Next     string `json:"next,omitempty"`
+	Prev     string `json:"prev,omitempty"`
+}
+
+// NewCursorPaginated builds a page from items fetched in display order. hasMore tells whether
+// more items exist beyond the page in the fetch direction, keysOf returns the cursor keys of an item.
+func NewCursorPaginated[D any](items []*D, filter *CursorPageFilter, hasMore bool, keysOf func(item *D) []CursorKey) *CursorPaginated[D] {
+	page := &CursorPaginated[D]{
+		Data:       items,
+		Pagination: &CursorPaginationData{PageSize: filter.PageSize},
+	}
+	if len(items) == 0 {
+		return page
+	}
+	sort := filter.SortWithTieBreaker()
+	first := &Cursor{Sort: sort, Keys: keysOf(items[0]), Backward: true}
+	last := &Cursor{Sort: sort, Keys: keysOf(items[len(items)-1])}
+	if filter.IsBackward() {
+		page.NextCursor = last
+		if hasMore {
+			page.PrevCursor = first
+		}
+	} else {
+		if hasMore {
+			page.NextCursor = last
+		}
+		if filter.Cursor != nil {
+			page.PrevCursor = first
+		}
+	}
+	return page
+}
+
+// ParseCursorPageFilter parses pageSize and either the cursor query parameter or, for the first page,
+// sort and sortType. The sort of a following page is always taken from its cursor.
+func ParseCursorPageFilter(queryParams map[string]string, options PageFilterOptions, codec *CursorCodec) (*CursorPageFilter, error) {
+	pageFilter, err := ParsePageFilter(queryParams, options)
+	if err != nil {
+		return nil, err
+	}
+	filter := &CursorPageFilter{
+		PageSize: pageFilter.PageSize,
+		Sort:     pageFilter.Sort,
+	}
+
+	token := queryParams["cursor"]
+	if token == "" {
+		return filter, nil
+	}
+	cursor, err := codec.Decode(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(cursor.Sort) == 0 || len(cursor.Keys) != len(cursor.Sort) {
+		return nil, invalidCursor("Cursor keys do not match its sort", nil)
+	}
+	for _, sortOrder := range cursor.Sort {
+		if sortOrder.Field != IdField && !isSortableField(sortOrder.Field, options.SortableFields) {
+			return nil, invalidCursor(fmt.Sprintf("Cursor sorts by '%s' which is not sortable", sortOrder.Field), nil)
+		}
+	}
+	filter.Sort = cursor.Sort
+	filter.Cursor = cursor
+	return filter, nil
+}
+
+// CursorCodec signs and verifies continuation tokens so that clients can not forge them
+type CursorCodec struct {
+	key []byte
+}
+
+func NewCursorCodec(key []byte) *CursorCodec {
+	return &CursorCodec{
+		key: key,
+	}
+}
+
+// Encode returns the opaque continuation token of the cursor
+func (c *CursorCodec) Encode(cursor *Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", apperrors.InternalServerError("Failed to encode cursor", err)
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + c.sign(encodedPayload), nil
+}
+
+// Decode verifies the continuation token and returns its cursor
+func (c *CursorCodec) Decode(token string) (*Cursor, error) {
+	encodedPayload, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(c.sign(encodedPayload))) {
+		return nil, invalidCursor("Cursor signature is invalid", nil)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, invalidCursor("Cursor is not valid base64", err)
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, invalidCursor("Cursor payload is invalid", err)
+	}
+	return &cursor, nil
+}
+
+func (c *CursorCodec) sign(encodedPayload string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// EncodeCursorPage sets next and prev continuation tokens of the page
+func EncodeCursorPage[D any](codec *CursorCodec, page *CursorPaginated[D]) error {
+	var err error
+	if page.NextCursor != nil {
+		if page.Pagination.Next, err = codec.Encode(page.NextCursor); err != nil {
+			return err
+		}
+	}
+	if page.PrevCursor != nil {
+		if page.Pagination.Prev, err = codec.Encode(page.PrevCursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSortableField(field string, sortableFields map[string]string) bool {
+	for _, sortableField := range sortableFields {
+		if sortableField == field {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidCursor(message string, cause error) error {
+	return apperrors.InvalidRequestParameterWithValidation(message, "cursor", "continuation token", cause)
+}
+
+type cursorKeyJson struct {
+	Field string          `json:"f"`
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v,omitempty"`
+}
+
+// MarshalJSON keeps the value type, so that a time or an integer is restored as such
+func (k CursorKey) MarshalJSON() ([]byte, error) {
+	var valueType string
+	var value interface{}
+	switch v := k.Value.(type) {
+	case nil:
+		valueType = "null"
+	case string:
+		valueType, value = "string", v
+	case bool:
+		valueType, value = "bool", v
+	case int:
+		valueType, value = "int", int64(v)
+	case int32:
+		valueType, value = "int", int64(v)
+	case int64:
+		valueType, value = "int", v
+	case float64:
+		valueType, value = "float", v
+	case time.Time:
+		valueType, value = "time", v.UTC().Format(time.RFC3339Nano)
+	default:
+		return nil, fmt.Errorf("unsupported cursor value type %T of field %s", k.Value, k.Field)
+	}
+	var raw json.RawMessage
+	if value != nil {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(cursorKeyJson{Field: k.Field, Type: valueType, Value: raw})
+}
+
+func (k *CursorKey) UnmarshalJSON(data []byte) error {
+	var encoded cursorKeyJson
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	k.Field = encoded.Field
+	switch encoded.Type {
+	case "null":
+		k.Value = nil
+		return nil
+	case "string":
+		var v string
+		err := json.Unmarshal(encoded.Value, &v)
+		k.Value = v
+		return err
+	case "bool":
+		var v bool
+		err := json.Unmarshal(encoded.Value, &v)
+		k.Value = v
+		return err
+	case "int":
+		var v int64
+		err := json.Unmarshal(encoded.Value, &v)
+		k.Value = v
+		return err
+	case "float":
+		var v float64
+		err := json.Unmarshal(encoded.Value, &v)
+		k.Value = v
+		return err
+	case "time":
+		var v string
+		if err := json.Unmarshal(encoded.Value, &v); err != nil {
+			return err
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		k.Value = parsed
+		return err
+	}
+	return fmt.Errorf("unsupported cursor value type %s", encoded.Type)
+}
+
+// CompareCursorValues compares two values of the same supported type, nil being the smallest value
+func CompareCursorValues(a interface{}, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch av := a.(type) {
+	case string:
+		return strings.Compare(av, b.(string))
+	case int64:
+		return compareOrdered(av, b.(int64))
+	case float64:
+		return compareOrdered(av, b.(float64))
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case bool:
+		bv := b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func compareOrdered[T int64 | float64](a T, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec := NewCursorCodec([]byte("key"))
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	cursor := &Cursor{
+		Sort: []SortOrder{{Field: "created", Type: SortDesc}, {Field: IdField, Type: SortDesc}},
+		Keys: []CursorKey{{Field: "created", Value: created}, {Field: IdField, Value: "order-1"}},
+	}
+	token, err := codec.Encode(cursor)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	payload, signature, _ := strings.Cut(token, ".")
+	tests := []struct {
+		name    string
+		codec   *CursorCodec
+		token   string
+		wantErr bool
+	}{
+		{name: "round trip", codec: codec, token: token},
+		{name: "other key", codec: NewCursorCodec([]byte("other")), token: token, wantErr: true},
+		{name: "tampered payload", codec: codec, token: "x" + payload + "." + signature, wantErr: true},
+		{name: "tampered signature", codec: codec, token: payload + "." + signature + "x", wantErr: true},
+		{name: "no signature", codec: codec, token: payload, wantErr: true},
+		{name: "empty", codec: codec, token: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoded, err := test.codec.Decode(test.token)
+			if test.wantErr {
+				if !apperrors.Is(err, apperrors.INVALID_REQUEST_PARAMETERS) {
+					t.Fatalf("Decode() error = %v, want INVALID_REQUEST_PARAMETERS", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, cursor) {
+				t.Errorf("Decode() = %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+}
+
+func TestCursorKeyKeepsValueTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"nil", nil, nil},
+		{"string", "abc", "abc"},
+		{"bool", true, true},
+		{"int", 7, int64(7)},
+		{"int64", int64(1) << 60, int64(1) << 60},
+		{"float", 1.5, 1.5},
+		{"time", time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := CursorKey{Field: "f", Value: test.value}.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			var key CursorKey
+			if err := key.UnmarshalJSON(data); err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(key.Value, test.want) {
+				t.Errorf("value %#v, want %#v", key.Value, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCursorPageFilter(t *testing.T) {
+	codec := NewCursorCodec([]byte("key"))
+	options := PageFilterOptions{SortableFields: map[string]string{"name": "name"}}
+	encode := func(cursor *Cursor) string {
+		token, err := codec.Encode(cursor)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return token
+	}
+	byName := []SortOrder{{Field: "name", Type: SortAsc}}
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		wantSort []SortOrder
+		wantErr  bool
+	}{
+		{name: "first page", params: map[string]string{"sort": "name"}, wantSort: byName},
+		{name: "sort of cursor wins", params: map[string]string{"sort": "name:desc", "cursor": encode(&Cursor{Sort: byName, Keys: []CursorKey{{Field: "name", Value: "a"}}})}, wantSort: byName},
+		{name: "keys do not match sort", params: map[string]string{"cursor": encode(&Cursor{Sort: byName})}, wantErr: true},
+		{name: "field is not sortable", params: map[string]string{"cursor": encode(&Cursor{Sort: []SortOrder{{Field: "secret", Type: SortAsc}}, Keys: []CursorKey{{Field: "secret", Value: "a"}}})}, wantErr: true},
+		{name: "forged cursor", params: map[string]string{"cursor": "e30.forged"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := ParseCursorPageFilter(test.params, options, codec)
+			if test.wantErr {
+				if !apperrors.Is(err, apperrors.INVALID_REQUEST_PARAMETERS) {
+					t.Fatalf("ParseCursorPageFilter() error = %v, want INVALID_REQUEST_PARAMETERS", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCursorPageFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(filter.Sort, test.wantSort) {
+				t.Errorf("sort %+v, want %+v", filter.Sort, test.wantSort)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package apperrors
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -12,12 +13,39 @@ type Error struct {
 	Cause               error
 	HttpStatusCode      int
 	Params              map[string]string
+	// Violations lists every invalid field of an INVALID_REQUEST_PARAMETERS error
+	Violations []FieldViolation
+	// stack of the caller of the constructor, see StackTrace
+	stack []uintptr
+}
+
+// FieldViolation describes an invalid request field. Field is the path of the field, e.g.
+// items[0].quantity, Rule the violated rule if known.
+type FieldViolation struct {
+	Field         string      `json:"field"`
+	Rule          string      `json:"rule,omitempty"`
+	Message       string      `json:"message"`
+	RejectedValue interface{} `json:"rejectedValue,omitempty"`
 }
 
 func (e *Error) Error() string {
 	return fmt.Sprintf("Code: %s, InternalDescription: %s, Cause: [%v]", e.ErrorCode, e.InternalDescription, e.Cause)
 }
 
+// Unwrap returns the cause, so that errors.Is and errors.As inspect it as well
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is an *Error with the same code, e.g. one of the sentinels like
+// ErrEntityNotFound. errors.Is calls it for every error of the chain, so errors.Is(err,
+// apperrors.ErrEntityNotFound) also matches a not found error which is the cause of another error,
+// the same as apperrors.Is.
+func (e *Error) Is(target error) bool {
+	targetError, ok := target.(*Error)
+	return ok && targetError.ErrorCode == e.ErrorCode
+}
+
 const (
 	INTERNAL_SERVER_ERROR      = "INTERNAL_SERVER_ERROR"
 	INVALID_REQUEST            = "INVALID_REQUEST"
@@ -25,41 +53,76 @@ const (
 	ENTITY_NOT_FOUND           = "ENTITY_NOT_FOUND"
 	ENTITY_ALREADY_EXIST       = "ENTITY_ALREADY_EXIST"
 	INSUFFICIENT_PERMISSION    = "INSUFFICIENT_PERMISSION"
+	RESOURCE_NOT_FOUND         = "RESOURCE_NOT_FOUND"
+	METHOD_NOT_ALLOWED         = "METHOD_NOT_ALLOWED"
+	CONCURRENT_MODIFICATION    = "CONCURRENT_MODIFICATION"
+	PRECONDITION_FAILED        = "PRECONDITION_FAILED"
+	INVALID_STATE_TRANSITION   = "INVALID_STATE_TRANSITION"
 )
 
-func Is(errorToCheck error, errorCode string) bool {
-	if errorToCheck == nil {
-		return false
+// Sentinels of the error codes to be used with errors.Is. They have the description and status of
+// their code, so a handler may return one as is, but are shared values which must not be modified.
+var (
+	ErrInternalServerError      = sentinel(INTERNAL_SERVER_ERROR, "Internal server error has occurred", http.StatusInternalServerError)
+	ErrInvalidRequest           = sentinel(INVALID_REQUEST, "Invalid request", http.StatusBadRequest)
+	ErrInvalidRequestParameters = sentinel(INVALID_REQUEST_PARAMETERS, "Invalid request parameters", http.StatusBadRequest)
+	ErrEntityNotFound           = sentinel(ENTITY_NOT_FOUND, "Entity not found", http.StatusNotFound)
+	ErrEntityAlreadyExist       = sentinel(ENTITY_ALREADY_EXIST, "Entity already exist", http.StatusUnprocessableEntity)
+	ErrInsufficientPermission   = sentinel(INSUFFICIENT_PERMISSION, "You do not have rights to perform this action on this entity", http.StatusForbidden)
+	ErrResourceNotFound         = sentinel(RESOURCE_NOT_FOUND, "Resource not found", http.StatusNotFound)
+	ErrMethodNotAllowed         = sentinel(METHOD_NOT_ALLOWED, "Method not allowed", http.StatusMethodNotAllowed)
+	ErrConcurrentModification   = sentinel(CONCURRENT_MODIFICATION, "Entity was modified concurrently, reload it and retry", http.StatusConflict)
+	ErrPreconditionFailed       = sentinel(PRECONDITION_FAILED, "Precondition failed", http.StatusPreconditionFailed)
+	ErrInvalidStateTransition   = sentinel(INVALID_STATE_TRANSITION, "Action is not allowed in the current state of the entity", http.StatusConflict)
+)
+
+func sentinel(errorCode string, description string, httpStatusCode int) *Error {
+	return &Error{
+		ErrorCode:           errorCode,
+		Description:         description,
+		InternalDescription: description,
+		HttpStatusCode:      httpStatusCode,
 	}
-	convertedErrorToCheck, ok := errorToCheck.(*Error)
-	if !ok {
-		return false
+}
+
+// Is reports whether any *Error in the chain of errorToCheck has errorCode, like errors.Is with the
+// sentinel of the code. An internal server error caused by a not found entity is both, use As to
+// get the error a response is created from.
+func Is(errorToCheck error, errorCode string) bool {
+	return errors.Is(errorToCheck, &Error{ErrorCode: errorCode})
+}
+
+// As returns the first *Error in the chain of err, it is the error a response is created from
+func As(err error) (*Error, bool) {
+	var appError *Error
+	if errors.As(err, &appError) {
+		return appError, true
 	}
-	return convertedErrorToCheck.ErrorCode == errorCode
+	return nil, false
 }
 
 func InternalServerError(message string, cause error) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           INTERNAL_SERVER_ERROR,
 		Description:         "Internal server error has occurred",
 		InternalDescription: message,
 		Cause:               cause,
 		HttpStatusCode:      http.StatusInternalServerError,
-	}
+	})
 }
 
 func InvalidRequest(message string, cause error) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           INVALID_REQUEST,
 		Description:         "Invalid request",
 		InternalDescription: message,
 		Cause:               cause,
 		HttpStatusCode:      http.StatusBadRequest,
-	}
+	})
 }
 
 func EntityNotFound(message string, key string, value string, cause error) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           ENTITY_NOT_FOUND,
 		Description:         "Entity not found",
 		InternalDescription: message,
@@ -68,22 +131,22 @@ func EntityNotFound(message string, key string, value string, cause error) (erro
 		Params: map[string]string{
 			key: value,
 		},
-	}
+	})
 }
 
 func EntityNotFoundForMultipleFields(message string, params map[string]string, cause error) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           ENTITY_NOT_FOUND,
 		Description:         "Entity not found",
 		InternalDescription: message,
 		Cause:               cause,
 		HttpStatusCode:      http.StatusNotFound,
 		Params:              params,
-	}
+	})
 }
 
 func InvalidRequestParameter(message string, paramName string) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           INVALID_REQUEST_PARAMETERS,
 		Description:         "Invalid request parameter",
 		InternalDescription: message,
@@ -92,11 +155,14 @@ func InvalidRequestParameter(message string, paramName string) (error *Error) {
 		Params: map[string]string{
 			"param": paramName,
 		},
-	}
+		Violations: []FieldViolation{
+			{Field: paramName, Message: message},
+		},
+	})
 }
 
 func InvalidRequestParameterWithValidation(message string, paramName string, rule string, cause error) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           INVALID_REQUEST_PARAMETERS,
 		Description:         "Invalid request parameter",
 		InternalDescription: message,
@@ -106,11 +172,35 @@ func InvalidRequestParameterWithValidation(message string, paramName string, rul
 			"param": paramName,
 			"rule":  rule,
 		},
+		Violations: []FieldViolation{
+			{Field: paramName, Rule: rule, Message: message},
+		},
+	})
+}
+
+// InvalidRequestParameters reports several invalid parameters at once. Params name the first
+// violation like InvalidRequestParameterWithValidation does, Violations list all of them.
+func InvalidRequestParameters(message string, violations []FieldViolation) (error *Error) {
+	var params map[string]string
+	if len(violations) > 0 {
+		params = map[string]string{
+			"param": violations[0].Field,
+			"rule":  violations[0].Rule,
+		}
 	}
+	return withStack(&Error{
+		ErrorCode:           INVALID_REQUEST_PARAMETERS,
+		Description:         "Invalid request parameters",
+		InternalDescription: message,
+		Cause:               nil,
+		HttpStatusCode:      http.StatusBadRequest,
+		Params:              params,
+		Violations:          violations,
+	})
 }
 
 func EntityAlreadyExist(message string, key string, value string, cause error) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           ENTITY_ALREADY_EXIST,
 		Description:         "Entity already exist",
 		InternalDescription: message,
@@ -119,15 +209,81 @@ func EntityAlreadyExist(message string, key string, value string, cause error) (
 		Params: map[string]string{
 			key: value,
 		},
-	}
+	})
 }
 
 func UnauthorizedInsufficientPermissions(message string) (error *Error) {
-	return &Error{
+	return withStack(&Error{
 		ErrorCode:           INSUFFICIENT_PERMISSION,
 		Description:         "You do not have rights to perform this action on this entity",
 		InternalDescription: message,
 		Cause:               nil,
 		HttpStatusCode:      http.StatusForbidden,
-	}
+	})
+}
+
+func ResourceNotFound(message string, path string) (error *Error) {
+	return withStack(&Error{
+		ErrorCode:           RESOURCE_NOT_FOUND,
+		Description:         "Resource not found",
+		InternalDescription: message,
+		Cause:               nil,
+		HttpStatusCode:      http.StatusNotFound,
+		Params: map[string]string{
+			"path": path,
+		},
+	})
+}
+
+func MethodNotAllowed(message string, method string) (error *Error) {
+	return withStack(&Error{
+		ErrorCode:           METHOD_NOT_ALLOWED,
+		Description:         "Method not allowed",
+		InternalDescription: message,
+		Cause:               nil,
+		HttpStatusCode:      http.StatusMethodNotAllowed,
+		Params: map[string]string{
+			"method": method,
+		},
+	})
+}
+
+func ConcurrentModification(message string, key string, value string, cause error) (error *Error) {
+	return withStack(&Error{
+		ErrorCode:           CONCURRENT_MODIFICATION,
+		Description:         "Entity was modified concurrently, reload it and retry",
+		InternalDescription: message,
+		Cause:               cause,
+		HttpStatusCode:      http.StatusConflict,
+		Params: map[string]string{
+			key: value,
+		},
+	})
+}
+
+func PreconditionFailed(message string, header string) (error *Error) {
+	return withStack(&Error{
+		ErrorCode:           PRECONDITION_FAILED,
+		Description:         "Precondition failed",
+		InternalDescription: message,
+		Cause:               nil,
+		HttpStatusCode:      http.StatusPreconditionFailed,
+		Params: map[string]string{
+			"header": header,
+		},
+	})
+}
+
+func InvalidStateTransition(message string, state string, action string) (error *Error) {
+	return withStack(&Error{
+		ErrorCode:           INVALID_STATE_TRANSITION,
+		Description:         "Action is not allowed in the current state of the entity",
+		InternalDescription: message,
+		Cause:               nil,
+		HttpStatusCode:      http.StatusConflict,
+		Params: map[string]string{
+			"state":  state,
+			"action": action,
+		},
+	})
 }
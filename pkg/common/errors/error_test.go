@@ -0,0 +1,109 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorChain(t *testing.T) {
+	notFound := EntityNotFound("Order order-1 not found", "id", "order-1", nil)
+	internal := InternalServerError("Failed to load order", notFound)
+	plain := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		err       error
+		code      string
+		sentinel  error
+		wantIs    bool
+		wantFirst string
+	}{
+		{name: "direct", err: notFound, code: ENTITY_NOT_FOUND, sentinel: ErrEntityNotFound, wantIs: true, wantFirst: ENTITY_NOT_FOUND},
+		{name: "wrapped with %w", err: fmt.Errorf("get order: %w", notFound), code: ENTITY_NOT_FOUND, sentinel: ErrEntityNotFound, wantIs: true, wantFirst: ENTITY_NOT_FOUND},
+		{name: "other code", err: notFound, code: CONCURRENT_MODIFICATION, sentinel: ErrConcurrentModification, wantFirst: ENTITY_NOT_FOUND},
+		{name: "outer error", err: internal, code: INTERNAL_SERVER_ERROR, sentinel: ErrInternalServerError, wantIs: true, wantFirst: INTERNAL_SERVER_ERROR},
+		{name: "cause of outer error", err: internal, code: ENTITY_NOT_FOUND, sentinel: ErrEntityNotFound, wantIs: true, wantFirst: INTERNAL_SERVER_ERROR},
+		{name: "joined", err: errors.Join(plain, notFound), code: ENTITY_NOT_FOUND, sentinel: ErrEntityNotFound, wantIs: true, wantFirst: ENTITY_NOT_FOUND},
+		{name: "plain error", err: plain, code: INTERNAL_SERVER_ERROR, sentinel: ErrInternalServerError},
+		{name: "nil", err: nil, code: INTERNAL_SERVER_ERROR, sentinel: ErrInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Is(tt.err, tt.code); got != tt.wantIs {
+				t.Errorf("Is(%s) = %v, want %v", tt.code, got, tt.wantIs)
+			}
+			if got := errors.Is(tt.err, tt.sentinel); got != tt.wantIs {
+				t.Errorf("errors.Is(sentinel of %s) = %v, want %v", tt.code, got, tt.wantIs)
+			}
+			first, ok := As(tt.err)
+			if ok != (tt.wantFirst != "") {
+				t.Fatalf("As() ok = %v, want %v", ok, tt.wantFirst != "")
+			}
+			if ok && first.ErrorCode != tt.wantFirst {
+				t.Errorf("As() = %s, want %s", first.ErrorCode, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestSentinels(t *testing.T) {
+	tests := []struct {
+		sentinel   *Error
+		created    *Error
+		wantStatus int
+	}{
+		{ErrInternalServerError, InternalServerError("", nil), http.StatusInternalServerError},
+		{ErrInvalidRequest, InvalidRequest("", nil), http.StatusBadRequest},
+		{ErrInvalidRequestParameters, InvalidRequestParameters("", nil), http.StatusBadRequest},
+		{ErrEntityNotFound, EntityNotFound("", "id", "1", nil), http.StatusNotFound},
+		{ErrEntityAlreadyExist, EntityAlreadyExist("", "id", "1", nil), http.StatusUnprocessableEntity},
+		{ErrInsufficientPermission, UnauthorizedInsufficientPermissions(""), http.StatusForbidden},
+		{ErrResourceNotFound, ResourceNotFound("", "/"), http.StatusNotFound},
+		{ErrMethodNotAllowed, MethodNotAllowed("", "GET"), http.StatusMethodNotAllowed},
+		{ErrConcurrentModification, ConcurrentModification("", "id", "1", nil), http.StatusConflict},
+		{ErrPreconditionFailed, PreconditionFailed("", "If-Match"), http.StatusPreconditionFailed},
+		{ErrInvalidStateTransition, InvalidStateTransition("", "PAID", "pay"), http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sentinel.ErrorCode, func(t *testing.T) {
+			if tt.sentinel.HttpStatusCode != tt.wantStatus || tt.created.HttpStatusCode != tt.wantStatus {
+				t.Errorf("status = %d and %d, want %d", tt.sentinel.HttpStatusCode, tt.created.HttpStatusCode, tt.wantStatus)
+			}
+			if tt.sentinel.Description != tt.created.Description {
+				t.Errorf("Description = %s, want %s", tt.sentinel.Description, tt.created.Description)
+			}
+			if !errors.Is(tt.created, tt.sentinel) {
+				t.Errorf("errors.Is(%s, sentinel) = false", tt.created.ErrorCode)
+			}
+		})
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          *Error
+		wantFunction string
+	}{
+		{"constructor records its caller", InternalServerError("failed", nil), "TestStackTrace"},
+		{"sentinel has no stack", ErrInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := tt.err.StackTrace()
+			if tt.wantFunction == "" {
+				if stack != "" {
+					t.Errorf("StackTrace() = %s, want empty", stack)
+				}
+				return
+			}
+			firstLine, _, _ := strings.Cut(stack, "\n")
+			if !strings.HasSuffix(firstLine, tt.wantFunction) {
+				t.Errorf("StackTrace() starts with %s, want %s", firstLine, tt.wantFunction)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package apperrors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const maxStackDepth = 32
+
+// withStack records the stack of the caller of the constructor creating e
+func withStack(e *Error) *Error {
+	stack := make([]uintptr, maxStackDepth)
+	// skip runtime.Callers, withStack and the constructor
+	e.stack = stack[:runtime.Callers(3, stack)]
+	return e
+}
+
+// StackTrace returns the stack the error was created at, one function and location per line. It
+// is meant for internal logs and never part of a response.
+func (e *Error) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// ETag formats an entity version as a strong entity tag
+func ETag(version int) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// SetETag adds the ETag header for the entity version to the response
+func SetETag(response events.APIGatewayProxyResponse, version int) events.APIGatewayProxyResponse {
+	response.Headers = setHeader(response.Headers, ETagHeader, ETag(version))
+	return response
+}
+
+// IfMatchVersion returns the entity version required by the If-Match header, or nil when the
+// header is absent or '*'
+func IfMatchVersion(request events.APIGatewayProxyRequest) (*int, error) {
+	header := strings.TrimSpace(GetHeader(request, IfMatchHeader))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	version, err := parseETagVersion(header)
+	if err != nil {
+		return nil, apperrors.InvalidRequestParameterWithValidation(fmt.Sprintf("Invalid %s header '%s'", IfMatchHeader, header), IfMatchHeader, "entity tag", err)
+	}
+	return &version, nil
+}
+
+// IfNoneMatch reports whether the If-None-Match header matches the entity version, in which
+// case a GET request should be answered with 304 Not Modified
+func IfNoneMatch(request events.APIGatewayProxyRequest, version int) bool {
+	header := strings.TrimSpace(GetHeader(request, IfNoneMatchHeader))
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if tagVersion, err := parseETagVersion(tag); err == nil && tagVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified builds a 304 response for the entity version
+func NotModified(version int) events.APIGatewayProxyResponse {
+	return SetETag(events.APIGatewayProxyResponse{StatusCode: http.StatusNotModified}, version)
+}
+
+func parseETagVersion(tag string) (int, error) {
+	tag = strings.TrimPrefix(tag, "W/")
+	return strconv.Atoi(strings.Trim(tag, "\""))
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"github.com/aws/aws-lambda-go/events"
+	"testing"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    *int
+		wantErr bool
+	}{
+		{name: "absent"},
+		{name: "any", header: "*"},
+		{name: "strong", header: `"3"`, want: intPointer(3)},
+		{name: "weak", header: `W/"3"`, want: intPointer(3)},
+		{name: "surrounding spaces", header: ` "3" `, want: intPointer(3)},
+		{name: "not a version", header: `"abc"`, wantErr: true},
+		{name: "list", header: `"3", "4"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{Headers: map[string]string{"if-match": tt.header}}
+			got, err := IfMatchVersion(request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IfMatchVersion() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !apperrors.Is(err, apperrors.INVALID_REQUEST_PARAMETERS) {
+				t.Errorf("IfMatchVersion() error = %v, want %s", err, apperrors.INVALID_REQUEST_PARAMETERS)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("IfMatchVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "absent", want: false},
+		{name: "any", header: "*", want: true},
+		{name: "same version", header: `"3"`, want: true},
+		{name: "weak tag", header: `W/"3"`, want: true},
+		{name: "other version", header: `"2"`, want: false},
+		{name: "list containing version", header: `"1", W/"2" , "3"`, want: true},
+		{name: "list with invalid tag", header: `"abc", "3"`, want: true},
+		{name: "list without version", header: `"1", "2"`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{Headers: map[string]string{IfNoneMatchHeader: tt.header}}
+			if got := IfNoneMatch(request, 3); got != tt.want {
+				t.Errorf("IfNoneMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	response := NotModified(3)
+	if response.StatusCode != 304 || response.Headers[ETagHeader] != `"3"` {
+		t.Errorf("NotModified() = %d %v, want 304 with ETag \"3\"", response.StatusCode, response.Headers)
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
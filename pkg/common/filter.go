@@ -0,0 +1,125 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MatchMode defines how a StringFilter value is compared with the stored value
+type MatchMode string
+
+const (
+	MatchExact      MatchMode = "exact"
+	MatchPrefix     MatchMode = "prefix"
+	MatchContains   MatchMode = "contains"
+	MatchIgnoreCase MatchMode = "icase"
+)
+
+// MaxStringFilterLength limits the length of user supplied filter values
+const MaxStringFilterLength = 256
+
+// StringFilter matches a string field. The value is always taken literally, it is never
+// interpreted as a pattern.
+type StringFilter struct {
+	Value string    `json:"value"`
+	Mode  MatchMode `json:"mode"`
+}
+
+// Matches reports whether value satisfies the filter
+func (f *StringFilter) Matches(value string) bool {
+	switch f.Mode {
+	case MatchPrefix:
+		return strings.HasPrefix(value, f.Value)
+	case MatchContains:
+		return strings.Contains(value, f.Value)
+	case MatchIgnoreCase:
+		return strings.EqualFold(value, f.Value)
+	default:
+		return value == f.Value
+	}
+}
+
+// Pattern returns an anchored regular expression equivalent to the filter with the value escaped.
+// Exact and prefix patterns are case-sensitive and anchored at the start, so they can use an index.
+func (f *StringFilter) Pattern() (pattern string, options string) {
+	quoted := regexp.QuoteMeta(f.Value)
+	switch f.Mode {
+	case MatchPrefix:
+		return "^" + quoted, ""
+	case MatchContains:
+		return quoted, ""
+	case MatchIgnoreCase:
+		return "^" + quoted + "$", "i"
+	default:
+		return "^" + quoted + "$", ""
+	}
+}
+
+// GetStringFilterFromQueryParams parses param given either as `param=value` (exact match) or as
+// `param[mode]=value`, e.g. `name[prefix]=abc`. Only allowedModes are accepted, all modes are
+// accepted when none is given. Nil is returned when the parameter is absent.
+func GetStringFilterFromQueryParams(queryParams url.Values, param string, allowedModes ...MatchMode) (*StringFilter, error) {
+	var filter *StringFilter
+	for key := range queryParams {
+		mode, ok := parseFilterKey(key, param)
+		if !ok {
+			continue
+		}
+		if filter != nil {
+			return nil, apperrors.InvalidRequestParameterWithValidation(fmt.Sprintf("Query parameter %s is given more than once", param), param, "single value", nil)
+		}
+		if !isAllowedMode(mode, allowedModes) {
+			return nil, apperrors.InvalidRequestParameterWithValidation(fmt.Sprintf("Match mode '%s' is not supported for %s", mode, param), param, fmt.Sprintf("match mode one of %s", joinModes(allowedModes)), nil)
+		}
+		value, err := GetSingleFilterByName(key, queryParams)
+		if err != nil {
+			return nil, err
+		}
+		filter = &StringFilter{Value: value, Mode: mode}
+	}
+	if filter == nil || filter.Value == "" {
+		return nil, nil
+	}
+	if len(filter.Value) > MaxStringFilterLength {
+		return nil, apperrors.InvalidRequestParameterWithValidation(fmt.Sprintf("Query parameter %s is longer than %d characters", param, MaxStringFilterLength), param, fmt.Sprintf("max length %d", MaxStringFilterLength), nil)
+	}
+	return filter, nil
+}
+
+func parseFilterKey(key string, param string) (MatchMode, bool) {
+	if key == param {
+		return MatchExact, true
+	}
+	if strings.HasPrefix(key, param+"[") && strings.HasSuffix(key, "]") {
+		return MatchMode(key[len(param)+1 : len(key)-1]), true
+	}
+	return "", false
+}
+
+func isAllowedMode(mode MatchMode, allowedModes []MatchMode) bool {
+	if len(allowedModes) == 0 {
+		allowedModes = allMatchModes
+	}
+	for _, allowed := range allowedModes {
+		if mode == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+var allMatchModes = []MatchMode{MatchExact, MatchPrefix, MatchContains, MatchIgnoreCase}
+
+func joinModes(modes []MatchMode) string {
+	if len(modes) == 0 {
+		modes = allMatchModes
+	}
+	names := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		names = append(names, string(mode))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStringFilterPattern(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      StringFilter
+		wantPattern string
+		wantOptions string
+		matches     []string
+		misses      []string
+	}{
+		{name: "exact", filter: StringFilter{Value: "abc", Mode: MatchExact}, wantPattern: "^abc$",
+			matches: []string{"abc"}, misses: []string{"ABC", "abcd", "xabc"}},
+		{name: "prefix", filter: StringFilter{Value: "abc", Mode: MatchPrefix}, wantPattern: "^abc",
+			matches: []string{"abc", "abcd"}, misses: []string{"xabc"}},
+		{name: "contains", filter: StringFilter{Value: "abc", Mode: MatchContains}, wantPattern: "abc",
+			matches: []string{"abc", "xabcx"}, misses: []string{"ab"}},
+		{name: "ignore case", filter: StringFilter{Value: "abc", Mode: MatchIgnoreCase}, wantPattern: "^abc$", wantOptions: "i",
+			matches: []string{"abc", "ABC"}, misses: []string{"abcd"}},
+		{name: "unknown mode is exact", filter: StringFilter{Value: "abc"}, wantPattern: "^abc$",
+			matches: []string{"abc"}, misses: []string{"abcd"}},
+		{name: "wildcards are escaped", filter: StringFilter{Value: ".*", Mode: MatchContains}, wantPattern: `\.\*`,
+			matches: []string{"a.*b"}, misses: []string{"anything"}},
+		{name: "anchors are escaped", filter: StringFilter{Value: "^a$", Mode: MatchPrefix}, wantPattern: `^\^a\$`,
+			matches: []string{"^a$b"}, misses: []string{"a"}},
+		{name: "groups and alternation are escaped", filter: StringFilter{Value: "(a|b)", Mode: MatchExact}, wantPattern: `^\(a\|b\)$`,
+			matches: []string{"(a|b)"}, misses: []string{"a", "b"}},
+		{name: "backslash is escaped", filter: StringFilter{Value: `a\d`, Mode: MatchExact}, wantPattern: `^a\\d$`,
+			matches: []string{`a\d`}, misses: []string{"a1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, options := tt.filter.Pattern()
+			if pattern != tt.wantPattern || options != tt.wantOptions {
+				t.Fatalf("Pattern() = %q, %q, want %q, %q", pattern, options, tt.wantPattern, tt.wantOptions)
+			}
+			if strings.Contains(options, "i") {
+				pattern = "(?i)" + pattern
+			}
+			compiled := regexp.MustCompile(pattern)
+			for _, value := range tt.matches {
+				if !compiled.MatchString(value) || !tt.filter.Matches(value) {
+					t.Errorf("%q should match", value)
+				}
+			}
+			for _, value := range tt.misses {
+				if compiled.MatchString(value) || tt.filter.Matches(value) {
+					t.Errorf("%q should not match", value)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStringFilterFromQueryParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		allowedModes []MatchMode
+		want         *StringFilter
+		wantErr      bool
+	}{
+		{name: "absent", query: "other=1"},
+		{name: "empty", query: "name="},
+		{name: "exact", query: "name=abc", want: &StringFilter{Value: "abc", Mode: MatchExact}},
+		{name: "mode", query: "name%5Bprefix%5D=a.c", want: &StringFilter{Value: "a.c", Mode: MatchPrefix}},
+		{name: "allowed mode", query: "name%5Bicase%5D=abc", allowedModes: []MatchMode{MatchIgnoreCase}, want: &StringFilter{Value: "abc", Mode: MatchIgnoreCase}},
+		{name: "mode not allowed", query: "name%5Bcontains%5D=abc", allowedModes: []MatchMode{MatchExact, MatchPrefix}, wantErr: true},
+		{name: "unknown mode", query: "name%5Bregex%5D=abc", wantErr: true},
+		{name: "given twice", query: "name=abc&name%5Bprefix%5D=a", wantErr: true},
+		{name: "too long", query: "name=" + strings.Repeat("a", MaxStringFilterLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := GetStringFilterFromQueryParams(query, "name", tt.allowedModes...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetStringFilterFromQueryParams() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !apperrors.Is(err, apperrors.INVALID_REQUEST_PARAMETERS) {
+				t.Errorf("GetStringFilterFromQueryParams() error = %v, want %s", err, apperrors.INVALID_REQUEST_PARAMETERS)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetStringFilterFromQueryParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
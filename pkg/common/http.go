@@ -2,10 +2,13 @@ package common
 
 import (
 	apperrors "common/errors"
+	"common/logging"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -29,45 +32,53 @@ func SerializeResponse(statusCode int, body interface{}) (events.APIGatewayProxy
 	}, nil
 }
 
-func SerializeError(err error) (events.APIGatewayProxyResponse, error) {
-	jsonBody := ""
-	statusCode := 500
-	switch err.(type) {
-	case *apperrors.Error:
-		commonError := err.(*apperrors.Error)
-		errorDto := ErrorResponseDto{
-			ErrorCode:   commonError.ErrorCode,
-			Description: commonError.Description,
-			Params:      commonError.Params,
-		}
-		jsonBody, err = toJSON(errorDto)
-		if err != nil {
-			jsonBody = serializeInternalServerError()
-		}
-		statusCode = commonError.HttpStatusCode
-		break
-	default:
-		jsonBody = serializeInternalServerError()
+// SerializeError converts err into an API Gateway error response. Trace and span ids from ctx are
+// included in the body so that a client error can be correlated with the service logs. The body is
+// an ErrorResponseDto or ProblemDetails depending on the error format selected in ctx.
+func SerializeError(ctx context.Context, err error) (events.APIGatewayProxyResponse, error) {
+	errorDto, statusCode := newErrorResponseDto(ctx, err)
+	var body interface{} = errorDto
+	contentType := "application/json"
+	if GetErrorFormat(ctx) == ErrorFormatProblem {
+		body = newProblemDetails(ctx, errorDto, statusCode, err)
+		contentType = ProblemJsonContentType
+	}
+	jsonBody, err := toJSON(body)
+	if err != nil {
+		jsonBody = fmt.Sprintf("{\"errorCode\": \"%s\", \"description\": \"Internal server error has occurred\"}", apperrors.INTERNAL_SERVER_ERROR)
+		statusCode = 500
+		contentType = "application/json"
 	}
 	return events.APIGatewayProxyResponse{
 		StatusCode: statusCode,
 		Body:       jsonBody,
 		Headers: map[string]string{
-			"Content-Type": "application/json",
+			"Content-Type": contentType,
 		},
 	}, nil
 }
 
-func serializeInternalServerError() string {
-	genericError := ErrorResponseDto{
+// newErrorResponseDto returns the response of the first *apperrors.Error in the chain of err, any
+// other error is an internal server error whose details are not exposed
+func newErrorResponseDto(ctx context.Context, err error) (ErrorResponseDto, int) {
+	traceId := logging.GetTraceId(ctx)
+	spanId := logging.GetSpanId(ctx)
+	if commonError, ok := apperrors.As(err); ok {
+		return ErrorResponseDto{
+			ErrorCode:   commonError.ErrorCode,
+			Description: commonError.Description,
+			TraceId:     traceId,
+			SpanId:      spanId,
+			Params:      commonError.Params,
+			Violations:  commonError.Violations,
+		}, commonError.HttpStatusCode
+	}
+	return ErrorResponseDto{
 		ErrorCode:   apperrors.INTERNAL_SERVER_ERROR,
 		Description: "Internal server error has occurred",
-	}
-	jsonBody, err := toJSON(genericError)
-	if err != nil {
-		return fmt.Sprintf("{\"code\": \"%s\", \"description\": \"Internal server error has occurred\"}", apperrors.INTERNAL_SERVER_ERROR)
-	}
-	return jsonBody
+		TraceId:     traceId,
+		SpanId:      spanId,
+	}, 500
 }
 
 func toJSON(input interface{}) (string, error) {
@@ -84,10 +95,15 @@ type ErrorResponseDto struct {
 	TraceId     string            `json:"traceId"`
 	SpanId      string            `json:"spanId"`
 	Params      map[string]string `json:"params,omitempty"`
+	// Violations lists every invalid field, so that a client can highlight all of them at once
+	Violations []apperrors.FieldViolation `json:"violations,omitempty"`
 }
 
 func GetTimestampFromQueryParams(queryParams url.Values, param string) (*time.Time, error) {
-	createdString := GetFilterByName(param, queryParams)
+	createdString, err := GetSingleFilterByName(param, queryParams)
+	if err != nil {
+		return nil, err
+	}
 	var created *time.Time
 	if len(createdString) > 0 {
 		innerCreated, err := time.Parse(time.RFC3339, createdString)
@@ -107,3 +123,41 @@ func GetFilterByName(name string, queryParams url.Values) string {
 	}
 	return filter
 }
+
+// GetSingleFilterByName returns the value of a query parameter which may be given only once
+func GetSingleFilterByName(name string, queryParams url.Values) (string, error) {
+	filterArray := queryParams[name]
+	if len(filterArray) > 1 {
+		return "", apperrors.InvalidRequestParameterWithValidation(fmt.Sprintf("Query parameter %s is given %d times", name, len(filterArray)), name, "single value", nil)
+	}
+	return GetFilterByName(name, queryParams), nil
+}
+
+// GetFiltersByName returns all values of a multi valued query parameter. Values can be given by
+// repeating the parameter (status=NEW&status=PAID) or comma separated (status=NEW,PAID).
+func GetFiltersByName(name string, queryParams url.Values) []string {
+	var filters []string
+	for _, value := range queryParams[name] {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				filters = append(filters, part)
+			}
+		}
+	}
+	return filters
+}
+
+// QueryValues returns query parameters of the request, multi value parameters included
+func QueryValues(request events.APIGatewayProxyRequest) url.Values {
+	queryParams := url.Values{}
+	for name, values := range request.MultiValueQueryStringParameters {
+		queryParams[name] = append([]string{}, values...)
+	}
+	for name, value := range request.QueryStringParameters {
+		if _, ok := queryParams[name]; !ok {
+			queryParams.Set(name, value)
+		}
+	}
+	return queryParams
+}
@@ -62,13 +62,13 @@ func (h *Handler) HandleLog(e *log.Entry) error {
 
 	// Print the log message without color formatting
 	fmt.Fprintf(h.Writer, "%s %10s [%-16s, %-16s] %-30s: %s",
-		currentTime, level, spanId, traceId, component, e.Message)
+		currentTime, level, spanId, traceId, component, RedactURI(e.Message))
 
 	for _, name := range names {
 		if name == "trace-id" || name == "span-id" || name == "component" {
 			continue
 		}
-		fmt.Fprintf(h.Writer, " %s=%v", name, e.Fields.Get(name))
+		fmt.Fprintf(h.Writer, " %s=%v", name, RedactField(name, e.Fields.Get(name)))
 	}
 
 	fmt.Fprintln(h.Writer)
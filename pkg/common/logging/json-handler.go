@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// DefaultJson handler outputting to stderr.
+var DefaultJson = NewJson(os.Stderr)
+
+// JsonHandler writes every entry as a single line JSON object, so it can be queried by field
+// in CloudWatch Logs Insights.
+type JsonHandler struct {
+	mu      sync.Mutex
+	Writer  io.Writer
+	encoder *json.Encoder
+}
+
+// NewJson handler.
+func NewJson(w io.Writer) *JsonHandler {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &JsonHandler{
+		Writer:  w,
+		encoder: encoder,
+	}
+}
+
+// HandleLog implements log.Handler.
+func (h *JsonHandler) HandleLog(e *log.Entry) error {
+	timestamp := e.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	record := map[string]interface{}{
+		"timestamp": timestamp.UTC().Format(time.RFC3339Nano),
+		"level":     Strings[e.Level],
+		"message":   RedactURI(e.Message),
+		XTraceId:    stringOrEmpty(e.Fields.Get(XTraceId)),
+		XSpanId:     stringOrEmpty(e.Fields.Get(XSpanId)),
+		Component:   stringOrEmpty(e.Fields.Get(Component)),
+	}
+	for _, name := range e.Fields.Names() {
+		if name == XTraceId || name == XSpanId || name == Component {
+			continue
+		}
+		key := name
+		if _, reserved := record[key]; reserved {
+			key = "fields." + name
+		}
+		record[key] = jsonValue(RedactField(name, e.Fields.Get(name)))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.encoder.Encode(record)
+}
+
+func stringOrEmpty(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonValue makes sure that the value can be encoded, values which can not are logged as strings
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	}
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return value
+}
@@ -3,6 +3,8 @@ package logging
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"math/rand"
 
@@ -13,6 +15,52 @@ const XTraceId = "trace-id"
 const XSpanId = "span-id"
 const Component = "component"
 
+const (
+	LogFormatEnv  = "LOG_FORMAT"
+	LogLevelEnv   = "LOG_LEVEL"
+	LogFormatJson = "json"
+	LogFormatText = "text"
+	defaultLevel  = log.InfoLevel
+	initComponent = "Logging"
+)
+
+// Init configures the global logger from LOG_FORMAT (json or text, defaults to text) and
+// LOG_LEVEL (debug, info, warn, error or fatal, defaults to info). Additional sensitive field names
+// can be configured in LOG_REDACT_FIELDS. Services call it once at startup.
+func Init() {
+	var warnings []string
+
+	format := strings.ToLower(strings.TrimSpace(os.Getenv(LogFormatEnv)))
+	switch format {
+	case LogFormatJson:
+		log.SetHandler(DefaultJson)
+	case LogFormatText, "":
+		log.SetHandler(Default)
+	default:
+		log.SetHandler(Default)
+		warnings = append(warnings, fmt.Sprintf("Invalid value for %s: '%s'. Using default: %s", LogFormatEnv, format, LogFormatText))
+	}
+
+	level := defaultLevel
+	if levelString := strings.TrimSpace(os.Getenv(LogLevelEnv)); levelString != "" {
+		parsedLevel, err := log.ParseLevel(levelString)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Invalid value for %s: '%s'. Using default: %s", LogLevelEnv, levelString, defaultLevel))
+		} else {
+			level = parsedLevel
+		}
+	}
+	log.SetLevel(level)
+
+	if redactFields := os.Getenv(LogRedactFieldsEnv); redactFields != "" {
+		AddSensitiveFields(strings.Split(redactFields, ",")...)
+	}
+
+	for _, warning := range warnings {
+		Log(context.Background(), initComponent).Warn(warning)
+	}
+}
+
 func Log(ctx context.Context, component string) *log.Entry {
 	return log.WithFields(log.Fields{
 		XTraceId:  ctx.Value(XTraceId),
@@ -25,9 +73,14 @@ func NewInitialContext() context.Context {
 	return AddTraceToContext(context.Background(), "")
 }
 
+// MaxTraceIdLength limits the length of trace ids accepted from clients and messages
+const MaxTraceIdLength = 64
+
+// AddTraceToContext stores traceId and a new span id in the context. A missing or invalid trace
+// id (see IsValidTraceId) is replaced with the span id.
 func AddTraceToContext(ctx context.Context, traceId string) context.Context {
 	spanId := generateSpanId()
-	if len(traceId) < 1 {
+	if !IsValidTraceId(traceId) {
 		traceId = spanId
 	}
 
@@ -37,6 +90,38 @@ func AddTraceToContext(ctx context.Context, traceId string) context.Context {
 		XSpanId, spanId)
 }
 
+// GetTraceId returns the trace id stored in the context or an empty string
+func GetTraceId(ctx context.Context) string {
+	return getContextString(ctx, XTraceId)
+}
+
+// GetSpanId returns the span id stored in the context or an empty string
+func GetSpanId(ctx context.Context) string {
+	return getContextString(ctx, XSpanId)
+}
+
+func getContextString(ctx context.Context, key string) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
+
+// IsValidTraceId reports whether traceId is a short id of letters, digits and '-', which is safe
+// to echo in response headers and log lines
+func IsValidTraceId(traceId string) bool {
+	if len(traceId) < 1 || len(traceId) > MaxTraceIdLength {
+		return false
+	}
+	for _, c := range traceId {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
 func generateSpanId() string {
 	b := make([]byte, 8)
 	rand.Read(b)
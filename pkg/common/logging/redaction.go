@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces sensitive values in log output
+const RedactedValue = "[REDACTED]"
+
+// RedactTag is the struct tag marking a field as sensitive, e.g. `log:"redact"`
+const RedactTag = "log"
+
+// LogRedactFieldsEnv holds a comma separated list of additional sensitive field names
+const LogRedactFieldsEnv = "LOG_REDACT_FIELDS"
+
+const maxRedactionDepth = 10
+
+var (
+	sensitiveFieldsMu sync.RWMutex
+	sensitiveFields   = map[string]bool{}
+
+	// the password is matched up to the last '@' before the path, so that a raw '@' in it is masked too
+	uriCredentialsPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.\-]*://[^:/@\s]*):([^\s/]*)@`)
+)
+
+func init() {
+	AddSensitiveFields("password", "secret", "token", "authorization", "cardNumber", "email")
+}
+
+// AddSensitiveFields registers field names whose values are always redacted. Matching ignores case,
+// '-' and '_', and also applies to names ending with a sensitive name (e.g. accessToken, db_password).
+func AddSensitiveFields(names ...string) {
+	sensitiveFieldsMu.Lock()
+	defer sensitiveFieldsMu.Unlock()
+	for _, name := range names {
+		if normalized := normalizeFieldName(name); normalized != "" {
+			sensitiveFields[normalized] = true
+		}
+	}
+}
+
+// IsSensitiveField reports whether values of the field with the given name must be redacted
+func IsSensitiveField(name string) bool {
+	normalized := normalizeFieldName(name)
+	if normalized == "" {
+		return false
+	}
+	sensitiveFieldsMu.RLock()
+	defer sensitiveFieldsMu.RUnlock()
+	for sensitive := range sensitiveFields {
+		if strings.HasSuffix(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURI masks the password part of every URI with credentials found in value
+func RedactURI(value string) string {
+	return uriCredentialsPattern.ReplaceAllString(value, "$1:"+RedactedValue+"@")
+}
+
+// Redact returns a copy of value which is safe to log. Strings and errors have URI credentials masked,
+// structs are converted to maps in which fields tagged `log:"redact"` or having a sensitive name are
+// replaced with RedactedValue, maps and slices are redacted element by element.
+func Redact(value interface{}) interface{} {
+	return redactValue(reflect.ValueOf(value), 0)
+}
+
+// RedactField redacts a single named value, e.g. a log field
+func RedactField(name string, value interface{}) interface{} {
+	if IsSensitiveField(name) {
+		return RedactedValue
+	}
+	return Redact(value)
+}
+
+func redactValue(value reflect.Value, depth int) interface{} {
+	if !value.IsValid() {
+		return nil
+	}
+	if depth > maxRedactionDepth {
+		return fmt.Sprintf("%v", value.Interface())
+	}
+	if value.CanInterface() {
+		if err, ok := value.Interface().(error); ok {
+			if value.Kind() == reflect.Pointer && value.IsNil() {
+				return nil
+			}
+			return RedactURI(err.Error())
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return RedactURI(value.String())
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return redactValue(value.Elem(), depth+1)
+	case reflect.Struct:
+		if !hasExportedFields(value.Type()) {
+			return value.Interface()
+		}
+		return redactStruct(value, depth)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return value.Interface()
+		}
+		result := make(map[string]interface{}, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			key := iterator.Key().String()
+			if IsSensitiveField(key) {
+				result[key] = RedactedValue
+			} else {
+				result[key] = redactValue(iterator.Value(), depth+1)
+			}
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+		result := make([]interface{}, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			result[i] = redactValue(value.Index(i), depth+1)
+		}
+		return result
+	}
+	if value.CanInterface() {
+		return value.Interface()
+	}
+	return nil
+}
+
+func redactStruct(value reflect.Value, depth int) map[string]interface{} {
+	valueType := value.Type()
+	result := make(map[string]interface{}, valueType.NumField())
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		if isRedactTagged(field) || IsSensitiveField(field.Name) || IsSensitiveField(name) {
+			result[name] = RedactedValue
+			continue
+		}
+		result[name] = redactValue(value.Field(i), depth+1)
+	}
+	return result
+}
+
+func fieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func isRedactTagged(field reflect.StructField) bool {
+	for _, option := range strings.Split(field.Tag.Get(RedactTag), ",") {
+		if strings.TrimSpace(option) == "redact" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasExportedFields(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+}
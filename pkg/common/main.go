@@ -3,21 +3,22 @@ package common
 import (
 	"log"
 	"os"
-	"strconv"
 	"time"
 )
 
+// GetEnvDuration reads a duration in ParseDuration format (e.g. 10s or bare nanoseconds) from the
+// environment. Prefer LoadConfig, which fails at cold start instead of falling back to the default.
 func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
 		return defaultValue
 	}
 
-	value, err := strconv.Atoi(valueStr)
+	value, err := ParseDuration(valueStr)
 	if err != nil {
-		log.Printf("Invalid value for %s: %v. Using default: %d", key, err, defaultValue)
+		log.Printf("Invalid value for %s: %v. Using default: %s", key, err, defaultValue)
 		return defaultValue
 	}
 
-	return time.Duration(value)
+	return value
 }
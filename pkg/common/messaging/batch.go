@@ -0,0 +1,60 @@
+package messaging
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxBatchSize is the most entries accepted by EventBridge PutEvents, SNS PublishBatch and
+	// SQS SendMessageBatch
+	MaxBatchSize = 10
+	// MaxBatchBytes is the largest total payload of one EventBridge, SNS or SQS batch call
+	MaxBatchBytes = 256 * 1024
+)
+
+// batch is a range [start, end) of messages sent in one call
+type batch struct {
+	start int
+	end   int
+}
+
+// splitBatches groups consecutive messages into batches of at most maxCount messages and
+// maxBytes bytes. A single message larger than maxBytes gets a batch of its own and is
+// rejected by the service.
+func splitBatches(bodies [][]byte, maxCount int, maxBytes int) []batch {
+	var batches []batch
+	start, size := 0, 0
+	for i, body := range bodies {
+		if i > start && (i-start == maxCount || size+len(body) > maxBytes) {
+			batches = append(batches, batch{start: start, end: i})
+			start, size = i, 0
+		}
+		size += len(body)
+	}
+	if start < len(bodies) {
+		batches = append(batches, batch{start: start, end: len(bodies)})
+	}
+	return batches
+}
+
+// FailedMessage is a message rejected by the messaging service
+type FailedMessage struct {
+	EventId string
+	Code    string
+	Message string
+}
+
+// PublishError lists the messages of a Publish call which were rejected, the other messages
+// were published
+type PublishError struct {
+	Failed []FailedMessage
+}
+
+func (e *PublishError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+	for _, failed := range e.Failed {
+		failures = append(failures, fmt.Sprintf("%s: %s %s", failed.EventId, failed.Code, failed.Message))
+	}
+	return fmt.Sprintf("failed to publish %d events: %s", len(e.Failed), strings.Join(failures, "; "))
+}
@@ -0,0 +1,41 @@
+package messaging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBatches(t *testing.T) {
+	bodies := func(sizes ...int) [][]byte {
+		result := make([][]byte, 0, len(sizes))
+		for _, size := range sizes {
+			result = append(result, make([]byte, size))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		bodies   [][]byte
+		maxCount int
+		maxBytes int
+		want     []batch
+	}{
+		{"no messages", nil, 10, 100, nil},
+		{"single message", bodies(10), 10, 100, []batch{{0, 1}}},
+		{"fits one batch", bodies(10, 20, 30), 10, 100, []batch{{0, 3}}},
+		{"split by count", bodies(1, 1, 1, 1, 1), 2, 100, []batch{{0, 2}, {2, 4}, {4, 5}}},
+		{"split by bytes", bodies(40, 40, 40), 10, 100, []batch{{0, 2}, {2, 3}}},
+		{"exactly max bytes", bodies(50, 50, 1), 10, 100, []batch{{0, 2}, {2, 3}}},
+		{"oversized message alone", bodies(10, 200, 10), 10, 100, []batch{{0, 1}, {1, 2}, {2, 3}}},
+		{"oversized first message", bodies(200, 10), 10, 100, []batch{{0, 1}, {1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitBatches(tt.bodies, tt.maxCount, tt.maxBytes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitBatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package messaging
+
+import (
+	"common/logging"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"runtime/debug"
+	"strconv"
+	"sync"
+)
+
+const consumerComponent = "SqsConsumer"
+
+// MessageMetadata describes the SQS message a handler is called with. The event fields are set
+// when the body is an Envelope, directly or inside an SNS notification.
+type MessageMetadata struct {
+	MessageId    string
+	ReceiveCount int
+	EventId      string
+	EventType    string
+	Source       string
+}
+
+// MessageHandler handles a decoded message. A returned error makes SQS deliver the message again,
+// unless it is wrapped with Permanent.
+type MessageHandler[M any] func(ctx context.Context, message M, metadata MessageMetadata) error
+
+// PoisonHandler is called with a message which is dropped because it can not be handled
+type PoisonHandler func(ctx context.Context, record events.SQSMessage, cause error)
+
+type SqsConsumerOptions struct {
+	// Concurrency is the number of messages handled at the same time, messages of the same FIFO
+	// message group are always handled one after another in order
+	Concurrency int
+	// MaxReceiveCount drops a message as poison when it is received more often, 0 leaves
+	// it to the redrive policy of the queue
+	MaxReceiveCount int
+	// OnPoison is called for every dropped message, the message is logged when it is nil
+	OnPoison PoisonHandler
+}
+
+func DefaultSqsConsumerOptions() SqsConsumerOptions {
+	return SqsConsumerOptions{
+		Concurrency: 10,
+	}
+}
+
+// SqsConsumer is a Lambda handler for SQS events which decodes every message into M. Failed messages
+// are reported in BatchItemFailures, which requires ReportBatchItemFailures to be enabled on the
+// event source mapping.
+type SqsConsumer[M any] struct {
+	handler MessageHandler[M]
+	options SqsConsumerOptions
+}
+
+func NewSqsConsumer[M any](handler MessageHandler[M], options SqsConsumerOptions) *SqsConsumer[M] {
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	return &SqsConsumer[M]{
+		handler: handler,
+		options: options,
+	}
+}
+
+// Handle handles the messages of the batch and reports the ones to be delivered again
+func (c *SqsConsumer[M]) Handle(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	var mu sync.Mutex
+	var failures []events.SQSBatchItemFailure
+	semaphore := make(chan struct{}, c.options.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, group := range groupRecords(event.Records) {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(group []events.SQSMessage) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			for i, record := range group {
+				if c.handleRecord(ctx, record) {
+					continue
+				}
+				// the rest of a FIFO group must not be handled before the failed message
+				mu.Lock()
+				for _, failed := range group[i:] {
+					failures = append(failures, events.SQSBatchItemFailure{ItemIdentifier: failed.MessageId})
+				}
+				mu.Unlock()
+				return
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
+}
+
+// handleRecord returns false when the message has to be delivered again
+func (c *SqsConsumer[M]) handleRecord(ctx context.Context, record events.SQSMessage) (handled bool) {
+	message, metadata, traceId, decodeErr := decodeRecord[M](record)
+	ctx = logging.AddTraceToContext(ctx, traceId)
+	logger := logging.Log(ctx, consumerComponent).
+		WithField("messageId", record.MessageId).
+		WithField("eventType", metadata.EventType)
+
+	if decodeErr != nil {
+		c.poison(ctx, record, Permanent(decodeErr))
+		return true
+	}
+	if c.options.MaxReceiveCount > 0 && metadata.ReceiveCount > c.options.MaxReceiveCount {
+		c.poison(ctx, record, fmt.Errorf("message was received %d times", metadata.ReceiveCount))
+		return true
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logger.WithField("stack", string(debug.Stack())).Errorf("Message handler panicked: %v", recovered)
+			handled = false
+		}
+	}()
+	err := c.handler(ctx, message, metadata)
+	if err == nil {
+		return true
+	}
+	if IsPermanent(err) {
+		c.poison(ctx, record, err)
+		return true
+	}
+	logger.WithError(err).Warnf("Message handling failed on receive %d, it is delivered again", metadata.ReceiveCount)
+	return false
+}
+
+func (c *SqsConsumer[M]) poison(ctx context.Context, record events.SQSMessage, cause error) {
+	if c.options.OnPoison != nil {
+		c.options.OnPoison(ctx, record, cause)
+		return
+	}
+	logging.Log(ctx, consumerComponent).
+		WithField("messageId", record.MessageId).
+		WithField("eventSourceArn", record.EventSourceARN).
+		WithError(cause).
+		Error("Dropping message which can not be handled")
+}
+
+// groupRecords keeps records of a FIFO message group together in order, other records are
+// groups of their own
+func groupRecords(records []events.SQSMessage) [][]events.SQSMessage {
+	var groups [][]events.SQSMessage
+	groupIndex := map[string]int{}
+	for _, record := range records {
+		groupId := record.Attributes["MessageGroupId"]
+		if groupId == "" {
+			groups = append(groups, []events.SQSMessage{record})
+			continue
+		}
+		if index, ok := groupIndex[groupId]; ok {
+			groups[index] = append(groups[index], record)
+			continue
+		}
+		groupIndex[groupId] = len(groups)
+		groups = append(groups, []events.SQSMessage{record})
+	}
+	return groups
+}
+
+// snsNotification is the body of an SQS message delivered from SNS without raw message delivery
+type snsNotification struct {
+	Type      string `json:"Type"`
+	MessageId string `json:"MessageId"`
+	Message   string `json:"Message"`
+}
+
+// decodeRecord unwraps an SNS notification and an Envelope from the body, if present, and decodes
+// the remaining JSON into M
+func decodeRecord[M any](record events.SQSMessage) (M, MessageMetadata, string, error) {
+	var message M
+	metadata := MessageMetadata{MessageId: record.MessageId}
+	metadata.ReceiveCount, _ = strconv.Atoi(record.Attributes["ApproximateReceiveCount"])
+
+	body := []byte(record.Body)
+	var notification snsNotification
+	if json.Unmarshal(body, &notification) == nil && notification.Type == "Notification" {
+		body = []byte(notification.Message)
+	}
+
+	var envelope Envelope
+	traceId := ""
+	if json.Unmarshal(body, &envelope) == nil && envelope.SpecVersion != "" {
+		metadata.EventId = envelope.Id
+		metadata.EventType = envelope.Type
+		metadata.Source = envelope.Source
+		traceId = envelope.TraceId
+		body = envelope.Data
+	}
+
+	if err := json.Unmarshal(body, &message); err != nil {
+		return message, metadata, traceId, fmt.Errorf("failed to decode message %s: %w", record.MessageId, err)
+	}
+	return message, metadata, traceId, nil
+}
+
+type permanentError struct {
+	cause error
+}
+
+func (e *permanentError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.cause
+}
+
+// Permanent marks a handler error which would repeat on every delivery, the message is dropped
+// as poison instead of being delivered again
+func Permanent(err error) error {
+	if err == nil || IsPermanent(err) {
+		return err
+	}
+	return &permanentError{cause: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,218 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type testMessage struct {
+	Result string `json:"result"`
+}
+
+func testRecord(id string, groupId string, result string) events.SQSMessage {
+	attributes := map[string]string{"ApproximateReceiveCount": "1"}
+	if groupId != "" {
+		attributes["MessageGroupId"] = groupId
+	}
+	return events.SQSMessage{
+		MessageId:  id,
+		Body:       `{"result":"` + result + `"}`,
+		Attributes: attributes,
+	}
+}
+
+func messageIds(records []events.SQSMessage) []string {
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.MessageId)
+	}
+	return ids
+}
+
+func TestGroupRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []events.SQSMessage
+		want    [][]string
+	}{
+		{"no records", nil, nil},
+		{"standard queue", []events.SQSMessage{testRecord("1", "", "ok"), testRecord("2", "", "ok")}, [][]string{{"1"}, {"2"}}},
+		{"one group", []events.SQSMessage{testRecord("1", "a", "ok"), testRecord("2", "a", "ok")}, [][]string{{"1", "2"}}},
+		{"interleaved groups keep order", []events.SQSMessage{
+			testRecord("1", "a", "ok"), testRecord("2", "b", "ok"), testRecord("3", "a", "ok"), testRecord("4", "b", "ok"),
+		}, [][]string{{"1", "3"}, {"2", "4"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, group := range groupRecords(tt.records) {
+				got = append(got, messageIds(group))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSqsConsumerHandle(t *testing.T) {
+	tests := []struct {
+		name            string
+		records         []events.SQSMessage
+		maxReceiveCount int
+		wantFailures    []string
+		wantHandled     []string
+		wantPoisoned    []string
+	}{
+		{
+			name:        "all handled",
+			records:     []events.SQSMessage{testRecord("1", "", "ok"), testRecord("2", "", "ok")},
+			wantHandled: []string{"1", "2"},
+		},
+		{
+			name:         "partial failure",
+			records:      []events.SQSMessage{testRecord("1", "", "ok"), testRecord("2", "", "fail"), testRecord("3", "", "ok")},
+			wantFailures: []string{"2"},
+			wantHandled:  []string{"1", "2", "3"},
+		},
+		{
+			name: "failure stops its fifo group",
+			records: []events.SQSMessage{
+				testRecord("1", "a", "ok"), testRecord("2", "a", "fail"), testRecord("3", "a", "ok"), testRecord("4", "b", "ok"),
+			},
+			wantFailures: []string{"2", "3"},
+			wantHandled:  []string{"1", "2", "4"},
+		},
+		{
+			name:         "permanent error is poison",
+			records:      []events.SQSMessage{testRecord("1", "a", "permanent"), testRecord("2", "a", "ok")},
+			wantHandled:  []string{"1", "2"},
+			wantPoisoned: []string{"1"},
+		},
+		{
+			name:         "undecodable message is poison",
+			records:      []events.SQSMessage{{MessageId: "1", Body: "not json"}},
+			wantPoisoned: []string{"1"},
+		},
+		{
+			name:         "panic is delivered again",
+			records:      []events.SQSMessage{testRecord("1", "", "panic"), testRecord("2", "", "ok")},
+			wantFailures: []string{"1"},
+			wantHandled:  []string{"1", "2"},
+		},
+		{
+			name: "received too often is poison",
+			records: []events.SQSMessage{{
+				MessageId:  "1",
+				Body:       `{"result":"fail"}`,
+				Attributes: map[string]string{"ApproximateReceiveCount": strconv.Itoa(4)},
+			}},
+			maxReceiveCount: 3,
+			wantPoisoned:    []string{"1"},
+		},
+		{
+			name: "no receive limit by default",
+			records: []events.SQSMessage{{
+				MessageId:  "1",
+				Body:       `{"result":"fail"}`,
+				Attributes: map[string]string{"ApproximateReceiveCount": strconv.Itoa(100)},
+			}},
+			wantFailures: []string{"1"},
+			wantHandled:  []string{"1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var handled, poisoned []string
+			handler := func(ctx context.Context, message testMessage, metadata MessageMetadata) error {
+				mu.Lock()
+				handled = append(handled, metadata.MessageId)
+				mu.Unlock()
+				switch message.Result {
+				case "fail":
+					return errors.New("failed")
+				case "permanent":
+					return Permanent(errors.New("failed"))
+				case "panic":
+					panic("handler panicked")
+				}
+				return nil
+			}
+			options := DefaultSqsConsumerOptions()
+			options.MaxReceiveCount = tt.maxReceiveCount
+			options.OnPoison = func(ctx context.Context, record events.SQSMessage, cause error) {
+				mu.Lock()
+				poisoned = append(poisoned, record.MessageId)
+				mu.Unlock()
+			}
+
+			response, err := NewSqsConsumer(handler, options).Handle(context.Background(), events.SQSEvent{Records: tt.records})
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			var failures []string
+			for _, failure := range response.BatchItemFailures {
+				failures = append(failures, failure.ItemIdentifier)
+			}
+			sort.Strings(failures)
+			sort.Strings(handled)
+			if !reflect.DeepEqual(failures, tt.wantFailures) {
+				t.Errorf("failures = %v, want %v", failures, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(handled, tt.wantHandled) {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if !reflect.DeepEqual(poisoned, tt.wantPoisoned) {
+				t.Errorf("poisoned = %v, want %v", poisoned, tt.wantPoisoned)
+			}
+		})
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	envelope := `{"specversion":"1.0","id":"event-1","source":"payment","type":"payment.succeeded","traceid":"trace-1","data":{"result":"ok"}}`
+	notification := `{"Type":"Notification","MessageId":"sns-1","Message":` + strconv.Quote(envelope) + `}`
+
+	tests := []struct {
+		name         string
+		body         string
+		want         testMessage
+		wantMetadata MessageMetadata
+		wantTraceId  string
+		wantErr      bool
+	}{
+		{name: "plain", body: `{"result":"ok"}`, want: testMessage{Result: "ok"}, wantMetadata: MessageMetadata{MessageId: "1", ReceiveCount: 2}},
+		{name: "envelope", body: envelope, want: testMessage{Result: "ok"}, wantTraceId: "trace-1", wantMetadata: MessageMetadata{
+			MessageId: "1", ReceiveCount: 2, EventId: "event-1", EventType: "payment.succeeded", Source: "payment",
+		}},
+		{name: "envelope in sns notification", body: notification, want: testMessage{Result: "ok"}, wantTraceId: "trace-1", wantMetadata: MessageMetadata{
+			MessageId: "1", ReceiveCount: 2, EventId: "event-1", EventType: "payment.succeeded", Source: "payment",
+		}},
+		{name: "invalid", body: `{"result":`, wantErr: true, wantMetadata: MessageMetadata{MessageId: "1", ReceiveCount: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := events.SQSMessage{MessageId: "1", Body: tt.body, Attributes: map[string]string{"ApproximateReceiveCount": "2"}}
+			got, metadata, traceId, err := decodeRecord[testMessage](record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeRecord() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeRecord() message = %v, want %v", got, tt.want)
+			}
+			if metadata != tt.wantMetadata {
+				t.Errorf("decodeRecord() metadata = %+v, want %+v", metadata, tt.wantMetadata)
+			}
+			if traceId != tt.wantTraceId {
+				t.Errorf("decodeRecord() trace id = %s, want %s", traceId, tt.wantTraceId)
+			}
+		})
+	}
+}
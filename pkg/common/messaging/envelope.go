@@ -0,0 +1,77 @@
+package messaging
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+const CloudEventsSpecVersion = "1.0"
+
+// Envelope is a CloudEvents 1.0 event in structured JSON mode. TraceId is an extension attribute
+// carrying the trace id of the publisher, so consumers can continue the trace.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	Id              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+	TraceId         string          `json:"traceid,omitempty"`
+}
+
+// NewEnvelope wraps the event published by source. A RawEvent keeps its id, time and trace id,
+// other events get a new id, the current time and the trace id of ctx.
+func NewEnvelope(ctx context.Context, source string, event Event) (*Envelope, error) {
+	envelope := &Envelope{
+		SpecVersion:     CloudEventsSpecVersion,
+		Source:          source,
+		Type:            event.EventType(),
+		DataContentType: "application/json",
+	}
+	if raw, ok := event.(RawEvent); ok {
+		envelope.Id = raw.Id
+		envelope.Time = raw.OccurredAt
+		envelope.Data = raw.Payload
+		envelope.TraceId = raw.TraceId
+		return envelope, nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, apperrors.InternalServerError("Failed to serialize event "+event.EventType(), err)
+	}
+	envelope.Id = uuid.NewString()
+	envelope.Time = time.Now().UTC()
+	envelope.Data = data
+	envelope.TraceId = logging.GetTraceId(ctx)
+	return envelope, nil
+}
+
+// Decode unmarshals the event data into target
+func (e *Envelope) Decode(target interface{}) error {
+	return json.Unmarshal(e.Data, target)
+}
+
+// encodeEnvelopes wraps and serializes the events
+func encodeEnvelopes(ctx context.Context, source string, events []Event) ([]*Envelope, [][]byte, error) {
+	envelopes := make([]*Envelope, 0, len(events))
+	bodies := make([][]byte, 0, len(events))
+	for _, event := range events {
+		envelope, err := NewEnvelope(ctx, source, event)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, nil, apperrors.InternalServerError("Failed to serialize event envelope "+envelope.Type, err)
+		}
+		envelopes = append(envelopes, envelope)
+		bodies = append(bodies, body)
+	}
+	return envelopes, bodies, nil
+}
@@ -0,0 +1,36 @@
+package messaging
+
+import "context"
+
+// Event is a domain event, EventType names it for subscribers, e.g. order.created
+type Event interface {
+	EventType() string
+}
+
+// Publisher delivers events to subscribers
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// EventRecorder collects the events of an aggregate during domain operations. Aggregates embed it
+// with `bson:"-" json:"-"` so that the events are never stored or serialized with them.
+type EventRecorder struct {
+	events []Event
+}
+
+// Record adds an event which happened to the aggregate
+func (r *EventRecorder) Record(event Event) {
+	r.events = append(r.events, event)
+}
+
+// Events returns the recorded events without removing them
+func (r *EventRecorder) Events() []Event {
+	return r.events
+}
+
+// PullEvents returns the recorded events and forgets them, so they are dispatched only once
+func (r *EventRecorder) PullEvents() []Event {
+	events := r.events
+	r.events = nil
+	return events
+}
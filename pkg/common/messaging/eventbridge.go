@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	apperrors "common/errors"
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// EventBridgeClient is the subset of the EventBridge API used by EventBridgePublisher
+type EventBridgeClient interface {
+	PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// EventBridgePublisher puts events to an event bus. The envelope is the event detail, the event
+// type its detail type, so rules can match on both.
+type EventBridgePublisher struct {
+	client       EventBridgeClient
+	eventBusName string
+	source       string
+}
+
+func NewEventBridgePublisher(cfg aws.Config, eventBusName string, source string) *EventBridgePublisher {
+	return NewEventBridgePublisherWithClient(eventbridge.NewFromConfig(cfg), eventBusName, source)
+}
+
+func NewEventBridgePublisherWithClient(client EventBridgeClient, eventBusName string, source string) *EventBridgePublisher {
+	return &EventBridgePublisher{
+		client:       client,
+		eventBusName: eventBusName,
+		source:       source,
+	}
+}
+
+func (p *EventBridgePublisher) Publish(ctx context.Context, events ...Event) error {
+	envelopes, bodies, err := encodeEnvelopes(ctx, p.source, events)
+	if err != nil {
+		return err
+	}
+
+	var failed []FailedMessage
+	for _, batch := range splitBatches(bodies, MaxBatchSize, MaxBatchBytes) {
+		entries := make([]types.PutEventsRequestEntry, 0, batch.end-batch.start)
+		for i := batch.start; i < batch.end; i++ {
+			entries = append(entries, types.PutEventsRequestEntry{
+				EventBusName: aws.String(p.eventBusName),
+				Source:       aws.String(p.source),
+				DetailType:   aws.String(envelopes[i].Type),
+				Detail:       aws.String(string(bodies[i])),
+				Time:         aws.Time(envelopes[i].Time),
+			})
+		}
+		output, err := p.client.PutEvents(ctx, &eventbridge.PutEventsInput{Entries: entries})
+		if err != nil {
+			return apperrors.InternalServerError("Failed to put events to EventBridge bus "+p.eventBusName, err)
+		}
+		if output.FailedEntryCount == 0 {
+			continue
+		}
+		for i, result := range output.Entries {
+			if result.ErrorCode != nil {
+				failed = append(failed, FailedMessage{
+					EventId: envelopes[batch.start+i].Id,
+					Code:    aws.ToString(result.ErrorCode),
+					Message: aws.ToString(result.ErrorMessage),
+				})
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return &PublishError{Failed: failed}
+	}
+	return nil
+}
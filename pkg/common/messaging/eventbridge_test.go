@@ -0,0 +1,153 @@
+package messaging
+
+import (
+	apperrors "common/errors"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rawEvents returns count events with the ids event-0, event-1, ...
+func rawEvents(count int) []Event {
+	events := make([]Event, 0, count)
+	for i := 0; i < count; i++ {
+		events = append(events, RawEvent{
+			Id:         fmt.Sprintf("event-%d", i),
+			Type:       "order.created",
+			Payload:    json.RawMessage(fmt.Sprintf(`{"number":%d}`, i)),
+			OccurredAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			TraceId:    "trace-1",
+		})
+	}
+	return events
+}
+
+// envelopeId returns the id of the envelope serialized in body
+func envelopeId(t *testing.T, body *string) string {
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(aws.ToString(body)), &envelope); err != nil {
+		t.Fatalf("message %s is not an envelope: %v", aws.ToString(body), err)
+	}
+	return envelope.Id
+}
+
+// publishFailure describes the expected outcome of a Publish call
+type publishFailure struct {
+	clientErr bool
+	rejected  []string
+}
+
+func checkPublishError(t *testing.T, err error, want publishFailure) {
+	t.Helper()
+	if want.clientErr {
+		if !apperrors.Is(err, apperrors.INTERNAL_SERVER_ERROR) {
+			t.Errorf("Publish() error = %v, want INTERNAL_SERVER_ERROR", err)
+		}
+		return
+	}
+	if len(want.rejected) == 0 {
+		if err != nil {
+			t.Errorf("Publish() error = %v", err)
+		}
+		return
+	}
+	var publishErr *PublishError
+	if !errors.As(err, &publishErr) {
+		t.Fatalf("Publish() error = %v, want a PublishError", err)
+	}
+	var rejected []string
+	for _, failed := range publishErr.Failed {
+		if failed.Code != "ThrottlingException" {
+			t.Errorf("failed message code = %s, want ThrottlingException", failed.Code)
+		}
+		rejected = append(rejected, failed.EventId)
+	}
+	if !reflect.DeepEqual(rejected, want.rejected) {
+		t.Errorf("rejected = %v, want %v", rejected, want.rejected)
+	}
+}
+
+type fakeEventBridgeClient struct {
+	inputs []*eventbridge.PutEventsInput
+	err    error
+	// reject holds the ids of the events whose entries fail
+	reject map[string]bool
+	t      *testing.T
+}
+
+func (c *fakeEventBridgeClient) PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	c.inputs = append(c.inputs, params)
+	if c.err != nil {
+		return nil, c.err
+	}
+	output := &eventbridge.PutEventsOutput{}
+	for _, entry := range params.Entries {
+		if c.reject[envelopeId(c.t, entry.Detail)] {
+			output.FailedEntryCount++
+			output.Entries = append(output.Entries, types.PutEventsResultEntry{ErrorCode: aws.String("ThrottlingException"), ErrorMessage: aws.String("Rate exceeded")})
+		} else {
+			output.Entries = append(output.Entries, types.PutEventsResultEntry{EventId: aws.String("eb-id")})
+		}
+	}
+	return output, nil
+}
+
+func TestEventBridgePublisher(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     int
+		clientErr  error
+		reject     map[string]bool
+		wantCalls  []int
+		wantFailed publishFailure
+	}{
+		{name: "single event", events: 1, wantCalls: []int{1}},
+		{name: "split into batches", events: MaxBatchSize + 1, wantCalls: []int{MaxBatchSize, 1}},
+		{name: "client error", events: 1, clientErr: errors.New("timeout"), wantCalls: []int{1}, wantFailed: publishFailure{clientErr: true}},
+		{name: "rejected entries", events: MaxBatchSize + 2, reject: map[string]bool{"event-3": true, "event-11": true},
+			wantCalls: []int{MaxBatchSize, 2}, wantFailed: publishFailure{rejected: []string{"event-3", "event-11"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeEventBridgeClient{err: tt.clientErr, reject: tt.reject, t: t}
+			err := NewEventBridgePublisherWithClient(client, "orders", "order").Publish(context.Background(), rawEvents(tt.events)...)
+			checkPublishError(t, err, tt.wantFailed)
+
+			var calls []int
+			var ids []string
+			for _, input := range client.inputs {
+				calls = append(calls, len(input.Entries))
+				for _, entry := range input.Entries {
+					if aws.ToString(entry.EventBusName) != "orders" || aws.ToString(entry.Source) != "order" || aws.ToString(entry.DetailType) != "order.created" {
+						t.Errorf("entry bus %s source %s detail type %s", aws.ToString(entry.EventBusName), aws.ToString(entry.Source), aws.ToString(entry.DetailType))
+					}
+					if !aws.ToTime(entry.Time).Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+						t.Errorf("entry time = %s, want the event time", aws.ToTime(entry.Time))
+					}
+					ids = append(ids, envelopeId(t, entry.Detail))
+				}
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("entries per call = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.clientErr == nil && !reflect.DeepEqual(ids, eventIds(tt.events)) {
+				t.Errorf("published %v, want %v", ids, eventIds(tt.events))
+			}
+		})
+	}
+}
+
+func eventIds(count int) []string {
+	ids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		ids = append(ids, fmt.Sprintf("event-%d", i))
+	}
+	return ids
+}
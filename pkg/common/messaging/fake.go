@@ -0,0 +1,81 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+)
+
+// FakePublisher records the envelopes a broker publisher would send, in the batches it would send
+// them, so tests and local runs can assert on published messages without AWS
+type FakePublisher struct {
+	mu      sync.Mutex
+	source  string
+	batches [][]*Envelope
+	err     error
+}
+
+func NewFakePublisher(source string) *FakePublisher {
+	return &FakePublisher{
+		source: source,
+	}
+}
+
+func (p *FakePublisher) Publish(ctx context.Context, events ...Event) error {
+	envelopes, bodies, err := encodeEnvelopes(ctx, p.source, events)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	for _, batch := range splitBatches(bodies, MaxBatchSize, MaxBatchBytes) {
+		p.batches = append(p.batches, envelopes[batch.start:batch.end])
+	}
+	return nil
+}
+
+// FailWith makes the following Publish calls fail with err until it is called with nil
+func (p *FakePublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Messages returns all recorded envelopes in publishing order
+func (p *FakePublisher) Messages() []*Envelope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var messages []*Envelope
+	for _, batch := range p.batches {
+		messages = append(messages, batch...)
+	}
+	return messages
+}
+
+// MessagesOfType returns the recorded envelopes of the event type
+func (p *FakePublisher) MessagesOfType(eventType string) []*Envelope {
+	var messages []*Envelope
+	for _, message := range p.Messages() {
+		if message.Type == eventType {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Batches returns the recorded envelopes grouped by the calls a broker publisher would make
+func (p *FakePublisher) Batches() [][]*Envelope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]*Envelope(nil), p.batches...)
+}
+
+// Reset forgets the recorded envelopes
+func (p *FakePublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches = nil
+}
@@ -0,0 +1,21 @@
+package messaging
+
+import (
+	"common/logging"
+	"context"
+)
+
+// LogPublisher only logs events, it is used until a service is connected to a message broker
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, events ...Event) error {
+	logger := logging.Log(ctx, "LogPublisher")
+	for _, event := range events {
+		logger.WithField("eventType", event.EventType()).WithField("event", event).Info("Published event")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package messaging
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RawEvent is an event which is already serialized, e.g. relayed from an outbox. Id is unique per
+// event and stays the same when the event is delivered again, so consumers can drop duplicates.
+type RawEvent struct {
+	Id         string          `json:"id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	TraceId    string          `json:"traceId,omitempty"`
+}
+
+func (e RawEvent) EventType() string {
+	return e.Type
+}
@@ -0,0 +1,93 @@
+package messaging
+
+import (
+	apperrors "common/errors"
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"strconv"
+	"strings"
+)
+
+// EventTypeAttribute is the message attribute holding the event type, subscriptions can filter on it
+const EventTypeAttribute = "eventType"
+
+// SnsClient is the subset of the SNS API used by SnsPublisher
+type SnsClient interface {
+	PublishBatch(ctx context.Context, params *sns.PublishBatchInput, optFns ...func(*sns.Options)) (*sns.PublishBatchOutput, error)
+}
+
+// SnsPublisher publishes envelopes to a topic. On a FIFO topic all events of the source are in one
+// message group and the event id deduplicates them.
+type SnsPublisher struct {
+	client   SnsClient
+	topicArn string
+	source   string
+}
+
+func NewSnsPublisher(cfg aws.Config, topicArn string, source string) *SnsPublisher {
+	return NewSnsPublisherWithClient(sns.NewFromConfig(cfg), topicArn, source)
+}
+
+func NewSnsPublisherWithClient(client SnsClient, topicArn string, source string) *SnsPublisher {
+	return &SnsPublisher{
+		client:   client,
+		topicArn: topicArn,
+		source:   source,
+	}
+}
+
+func (p *SnsPublisher) Publish(ctx context.Context, events ...Event) error {
+	envelopes, bodies, err := encodeEnvelopes(ctx, p.source, events)
+	if err != nil {
+		return err
+	}
+	fifo := strings.HasSuffix(p.topicArn, ".fifo")
+
+	var failed []FailedMessage
+	for _, batch := range splitBatches(bodies, MaxBatchSize, MaxBatchBytes) {
+		entries := make([]types.PublishBatchRequestEntry, 0, batch.end-batch.start)
+		for i := batch.start; i < batch.end; i++ {
+			entry := types.PublishBatchRequestEntry{
+				Id:      aws.String(strconv.Itoa(i)),
+				Message: aws.String(string(bodies[i])),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					EventTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String(envelopes[i].Type)},
+				},
+			}
+			if fifo {
+				entry.MessageGroupId = aws.String(p.source)
+				entry.MessageDeduplicationId = aws.String(envelopes[i].Id)
+			}
+			entries = append(entries, entry)
+		}
+		output, err := p.client.PublishBatch(ctx, &sns.PublishBatchInput{
+			TopicArn:                   aws.String(p.topicArn),
+			PublishBatchRequestEntries: entries,
+		})
+		if err != nil {
+			return apperrors.InternalServerError("Failed to publish events to SNS topic "+p.topicArn, err)
+		}
+		for _, result := range output.Failed {
+			failed = append(failed, failedBatchEntry(envelopes, result.Id, result.Code, result.Message))
+		}
+	}
+	if len(failed) > 0 {
+		return &PublishError{Failed: failed}
+	}
+	return nil
+}
+
+// failedBatchEntry maps a failed SNS or SQS batch entry, identified by its message index, to its event
+func failedBatchEntry(envelopes []*Envelope, id *string, code *string, message *string) FailedMessage {
+	failed := FailedMessage{
+		EventId: aws.ToString(id),
+		Code:    aws.ToString(code),
+		Message: aws.ToString(message),
+	}
+	if index, err := strconv.Atoi(failed.EventId); err == nil && index >= 0 && index < len(envelopes) {
+		failed.EventId = envelopes[index].Id
+	}
+	return failed
+}
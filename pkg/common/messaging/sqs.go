@@ -0,0 +1,77 @@
+package messaging
+
+import (
+	apperrors "common/errors"
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"strconv"
+	"strings"
+)
+
+// SqsClient is the subset of the SQS API used by SqsPublisher
+type SqsClient interface {
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+}
+
+// SqsPublisher sends envelopes to a queue. On a FIFO queue all events of the source are in one
+// message group and the event id deduplicates them.
+type SqsPublisher struct {
+	client   SqsClient
+	queueUrl string
+	source   string
+}
+
+func NewSqsPublisher(cfg aws.Config, queueUrl string, source string) *SqsPublisher {
+	return NewSqsPublisherWithClient(sqs.NewFromConfig(cfg), queueUrl, source)
+}
+
+func NewSqsPublisherWithClient(client SqsClient, queueUrl string, source string) *SqsPublisher {
+	return &SqsPublisher{
+		client:   client,
+		queueUrl: queueUrl,
+		source:   source,
+	}
+}
+
+func (p *SqsPublisher) Publish(ctx context.Context, events ...Event) error {
+	envelopes, bodies, err := encodeEnvelopes(ctx, p.source, events)
+	if err != nil {
+		return err
+	}
+	fifo := strings.HasSuffix(p.queueUrl, ".fifo")
+
+	var failed []FailedMessage
+	for _, batch := range splitBatches(bodies, MaxBatchSize, MaxBatchBytes) {
+		entries := make([]types.SendMessageBatchRequestEntry, 0, batch.end-batch.start)
+		for i := batch.start; i < batch.end; i++ {
+			entry := types.SendMessageBatchRequestEntry{
+				Id:          aws.String(strconv.Itoa(i)),
+				MessageBody: aws.String(string(bodies[i])),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					EventTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String(envelopes[i].Type)},
+				},
+			}
+			if fifo {
+				entry.MessageGroupId = aws.String(p.source)
+				entry.MessageDeduplicationId = aws.String(envelopes[i].Id)
+			}
+			entries = append(entries, entry)
+		}
+		output, err := p.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(p.queueUrl),
+			Entries:  entries,
+		})
+		if err != nil {
+			return apperrors.InternalServerError("Failed to send events to SQS queue "+p.queueUrl, err)
+		}
+		for _, result := range output.Failed {
+			failed = append(failed, failedBatchEntry(envelopes, result.Id, result.Code, result.Message))
+		}
+	}
+	if len(failed) > 0 {
+		return &PublishError{Failed: failed}
+	}
+	return nil
+}
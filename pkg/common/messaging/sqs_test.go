@@ -0,0 +1,92 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"reflect"
+	"testing"
+)
+
+type fakeSqsClient struct {
+	inputs []*sqs.SendMessageBatchInput
+	err    error
+	// reject holds the ids of the events whose entries fail
+	reject map[string]bool
+	t      *testing.T
+}
+
+func (c *fakeSqsClient) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	c.inputs = append(c.inputs, params)
+	if c.err != nil {
+		return nil, c.err
+	}
+	output := &sqs.SendMessageBatchOutput{}
+	for _, entry := range params.Entries {
+		if c.reject[envelopeId(c.t, entry.MessageBody)] {
+			output.Failed = append(output.Failed, types.BatchResultErrorEntry{Id: entry.Id, Code: aws.String("ThrottlingException"), Message: aws.String("Rate exceeded")})
+		} else {
+			output.Successful = append(output.Successful, types.SendMessageBatchResultEntry{Id: entry.Id})
+		}
+	}
+	return output, nil
+}
+
+func TestSqsPublisher(t *testing.T) {
+	tests := []struct {
+		name       string
+		queueUrl   string
+		events     int
+		clientErr  error
+		reject     map[string]bool
+		wantCalls  []int
+		wantFifo   bool
+		wantFailed publishFailure
+	}{
+		{name: "single event", queueUrl: "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", events: 1, wantCalls: []int{1}},
+		{name: "fifo queue", queueUrl: "https://sqs.eu-west-1.amazonaws.com/123456789012/orders.fifo", events: 2, wantCalls: []int{2}, wantFifo: true},
+		{name: "split into batches", queueUrl: "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", events: MaxBatchSize + 1, wantCalls: []int{MaxBatchSize, 1}},
+		{name: "client error", queueUrl: "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", events: 1, clientErr: errors.New("timeout"),
+			wantCalls: []int{1}, wantFailed: publishFailure{clientErr: true}},
+		{name: "rejected entries", queueUrl: "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", events: MaxBatchSize + 2,
+			reject: map[string]bool{"event-3": true, "event-11": true}, wantCalls: []int{MaxBatchSize, 2},
+			wantFailed: publishFailure{rejected: []string{"event-3", "event-11"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeSqsClient{err: tt.clientErr, reject: tt.reject, t: t}
+			err := NewSqsPublisherWithClient(client, tt.queueUrl, "order").Publish(context.Background(), rawEvents(tt.events)...)
+			checkPublishError(t, err, tt.wantFailed)
+
+			var calls []int
+			var ids []string
+			for _, input := range client.inputs {
+				if aws.ToString(input.QueueUrl) != tt.queueUrl {
+					t.Errorf("QueueUrl = %s, want %s", aws.ToString(input.QueueUrl), tt.queueUrl)
+				}
+				calls = append(calls, len(input.Entries))
+				for _, entry := range input.Entries {
+					id := envelopeId(t, entry.MessageBody)
+					ids = append(ids, id)
+					if eventType := aws.ToString(entry.MessageAttributes[EventTypeAttribute].StringValue); eventType != "order.created" {
+						t.Errorf("%s attribute = %s, want order.created", EventTypeAttribute, eventType)
+					}
+					if tt.wantFifo && (aws.ToString(entry.MessageGroupId) != "order" || aws.ToString(entry.MessageDeduplicationId) != id) {
+						t.Errorf("fifo entry group %s deduplication %s, want order and %s", aws.ToString(entry.MessageGroupId), aws.ToString(entry.MessageDeduplicationId), id)
+					}
+					if !tt.wantFifo && (entry.MessageGroupId != nil || entry.MessageDeduplicationId != nil) {
+						t.Errorf("standard queue entry has fifo attributes")
+					}
+				}
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("entries per call = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.clientErr == nil && !reflect.DeepEqual(ids, eventIds(tt.events)) {
+				t.Errorf("published %v, want %v", ids, eventIds(tt.events))
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/aws/aws-lambda-go/events"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const middlewareComponent = "HttpMiddleware"
+
+// Middleware wraps a HandlerFunc with additional behavior
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain wraps handler with middlewares, the first middleware being the outermost one
+func Chain(handler HandlerFunc, middlewares ...Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// DefaultMiddlewares returns the middleware stack every API Gateway service is expected to use
+func DefaultMiddlewares() []Middleware {
+	return []Middleware{
+		Tracing(),
+		ErrorFormatNegotiation(),
+		AccessLog(),
+		Timing(),
+		Recovery(),
+		ErrorHandling(),
+	}
+}
+
+// Tracing seeds the context with the trace id of the incoming request (see ExtractTraceId) and a new
+// span id, and echoes both back in the X-Trace-Id and X-Span-Id response headers
+func Tracing() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			ctx = logging.AddTraceToContext(ctx, ExtractTraceId(request))
+			response, err := next(ctx, request)
+			response.Headers = setHeader(response.Headers, TraceIdHeader, logging.GetTraceId(ctx))
+			response.Headers = setHeader(response.Headers, SpanIdHeader, logging.GetSpanId(ctx))
+			return response, err
+		}
+	}
+}
+
+// AccessLog logs every request and the response status together with its latency
+func AccessLog() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			start := time.Now()
+			logger := logging.Log(ctx, middlewareComponent).WithFields(log.Fields{
+				"method":    request.HTTPMethod,
+				"path":      request.Path,
+				"requestId": request.RequestContext.RequestID,
+			})
+			logger.Info("Request started")
+
+			response, err := next(ctx, request)
+
+			logger = logger.WithFields(log.Fields{
+				"status":   response.StatusCode,
+				"duration": time.Since(start).Milliseconds(),
+			})
+			if err != nil {
+				logger.WithError(err).Error("Request failed")
+			} else {
+				logger.Info("Request finished")
+			}
+			return response, err
+		}
+	}
+}
+
+// Timing measures handler latency and reports it in the Server-Timing response header
+func Timing() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			start := time.Now()
+			response, err := next(ctx, request)
+			elapsed := float64(time.Since(start).Microseconds()) / 1000
+			response.Headers = setHeader(response.Headers, "Server-Timing", fmt.Sprintf("app;dur=%.3f", elapsed))
+			return response, err
+		}
+	}
+}
+
+// Recovery converts a panic in the handler into an internal server error response
+func Recovery() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (response events.APIGatewayProxyResponse, err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logging.Log(ctx, middlewareComponent).
+						WithField("stack", string(debug.Stack())).
+						Errorf("Recovered from panic: %v", recovered)
+					response, err = SerializeError(ctx, apperrors.InternalServerError(fmt.Sprintf("Panic: %v", recovered), nil))
+				}
+			}()
+			return next(ctx, request)
+		}
+	}
+}
+
+// ErrorHandling serializes errors returned by the handler, so handlers can return them as is
+func ErrorHandling() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			response, err := next(ctx, request)
+			if err == nil {
+				return response, nil
+			}
+			logger := logging.Log(ctx, middlewareComponent).WithError(err)
+			// the first *apperrors.Error in the chain is the one the response is created from
+			if appError, ok := apperrors.As(err); !ok || appError.ErrorCode == apperrors.INTERNAL_SERVER_ERROR {
+				if ok {
+					logger = logger.WithField("stack", appError.StackTrace())
+				}
+				logger.Error("Request failed")
+			} else {
+				logger.Warn("Request failed")
+			}
+			return SerializeError(ctx, err)
+		}
+	}
+}
+
+// GetHeader returns the value of a request header, matching the name case-insensitively
+func GetHeader(request events.APIGatewayProxyRequest, name string) string {
+	if value, ok := request.Headers[name]; ok {
+		return value
+	}
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	for key, values := range request.MultiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func setHeader(headers map[string]string, name string, value string) map[string]string {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headers[name] = value
+	return headers
+}
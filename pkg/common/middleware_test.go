@@ -0,0 +1,130 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		name        string
+		middlewares []string
+		want        []string
+	}{
+		{name: "no middlewares", want: []string{"handler"}},
+		{name: "first is outermost", middlewares: []string{"a", "b", "c"}, want: []string{"a>", "b>", "c>", "handler", "<c", "<b", "<a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var middlewares []Middleware
+			for _, label := range tt.middlewares {
+				middlewares = append(middlewares, func(next HandlerFunc) HandlerFunc {
+					return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+						calls = append(calls, label+">")
+						defer func() { calls = append(calls, "<"+label) }()
+						return next(ctx, request)
+					}
+				})
+			}
+			handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				calls = append(calls, "handler")
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			}
+
+			if _, err := Chain(handler, middlewares...)(context.Background(), events.APIGatewayProxyRequest{}); err != nil {
+				t.Fatalf("Chain() error = %v", err)
+			}
+			if !reflect.DeepEqual(calls, tt.want) {
+				t.Errorf("calls = %v, want %v", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMiddlewares(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       HandlerFunc
+		headers       map[string]string
+		wantStatus    int
+		wantErrorCode string
+		wantTraceId   string
+	}{
+		{
+			name: "success",
+			handler: func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "{}"}, nil
+			},
+			headers:     map[string]string{TraceIdHeader: "client-trace"},
+			wantStatus:  http.StatusOK,
+			wantTraceId: "client-trace",
+		},
+		{
+			name: "panic is recovered",
+			handler: func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				panic("nil map")
+			},
+			wantStatus:    http.StatusInternalServerError,
+			wantErrorCode: apperrors.INTERNAL_SERVER_ERROR,
+		},
+		{
+			name: "app error is serialized",
+			handler: func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{}, apperrors.EntityNotFound("missing", "id", "order-1", nil)
+			},
+			wantStatus:    http.StatusNotFound,
+			wantErrorCode: apperrors.ENTITY_NOT_FOUND,
+		},
+		{
+			name: "plain error is an internal server error",
+			handler: func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{}, errors.New("connection refused")
+			},
+			wantStatus:    http.StatusInternalServerError,
+			wantErrorCode: apperrors.INTERNAL_SERVER_ERROR,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Chain(tt.handler, DefaultMiddlewares()...)(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Path:       "/orders",
+				Headers:    tt.headers,
+			})
+			if err != nil {
+				t.Fatalf("handler error = %v, want it serialized", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			traceId := response.Headers[TraceIdHeader]
+			if traceId == "" || (tt.wantTraceId != "" && traceId != tt.wantTraceId) {
+				t.Errorf("%s = %q, want %q", TraceIdHeader, traceId, tt.wantTraceId)
+			}
+			if response.Headers[SpanIdHeader] == "" {
+				t.Errorf("%s is missing", SpanIdHeader)
+			}
+			if !strings.HasPrefix(response.Headers["Server-Timing"], "app;dur=") {
+				t.Errorf("Server-Timing = %q", response.Headers["Server-Timing"])
+			}
+			if tt.wantErrorCode == "" {
+				return
+			}
+			var body ErrorResponseDto
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("body %s is not JSON: %v", response.Body, err)
+			}
+			if body.ErrorCode != tt.wantErrorCode || body.TraceId != traceId {
+				t.Errorf("body = %+v, want error %s with trace %s", body, tt.wantErrorCode, traceId)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"common/messaging"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Status string
+
+const (
+	// StatusPending entries wait to be published, possibly after failed attempts
+	StatusPending Status = "pending"
+	// StatusPublished entries were handed to the publisher
+	StatusPublished Status = "published"
+	// StatusDead entries failed RelayOptions.MaxAttempts times and are not retried any more
+	StatusDead Status = "dead"
+)
+
+// Entry is an event stored in the outbox together with the change which caused it. Id is
+// generated once and published as the event id, so consumers can drop duplicate deliveries.
+type Entry struct {
+	Id        string `bson:"_id"`
+	EventType string `bson:"eventType"`
+	// Payload is the event serialized to JSON
+	Payload string `bson:"payload"`
+	TraceId string `bson:"traceId,omitempty"`
+	Status  Status `bson:"status"`
+	// Attempts counts the claims of the entry by a relay
+	Attempts int `bson:"attempts"`
+	// NextAttemptAt is when the entry may be claimed, it is moved forward on claim and after a failure
+	NextAttemptAt time.Time  `bson:"nextAttemptAt"`
+	LastError     string     `bson:"lastError,omitempty"`
+	Created       time.Time  `bson:"created"`
+	Published     *time.Time `bson:"published,omitempty"`
+}
+
+// NewEntry serializes the event into a pending entry carrying the trace id of ctx
+func NewEntry(ctx context.Context, event messaging.Event) (*Entry, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, apperrors.InternalServerError("Failed to serialize event "+event.EventType(), err)
+	}
+	now := time.Now().UTC()
+	return &Entry{
+		Id:            uuid.NewString(),
+		EventType:     event.EventType(),
+		Payload:       string(payload),
+		TraceId:       logging.GetTraceId(ctx),
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		Created:       now,
+	}, nil
+}
+
+// RawEvent returns the event of the entry as it is published
+func (e *Entry) RawEvent() messaging.RawEvent {
+	return messaging.RawEvent{
+		Id:         e.Id,
+		Type:       e.EventType,
+		Payload:    json.RawMessage(e.Payload),
+		OccurredAt: e.Created,
+		TraceId:    e.TraceId,
+	}
+}
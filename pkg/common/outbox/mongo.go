@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	apperrors "common/errors"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// MaxErrorLength limits the stored error message of a failed attempt
+const MaxErrorLength = 1024
+
+// MongoStore keeps the outbox in a collection of the database the changes are stored in
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(client *mongo.Client, database string) *MongoStore {
+	return &MongoStore{
+		collection: client.Database(database).Collection("outbox"),
+	}
+}
+
+// namespaceExists is the code of the server error for a collection which already exists
+const namespaceExists = 48
+
+// EnsureIndexes creates the outbox collection and the index used to claim due entries. Entries are
+// added in transactions, which can not create collections before MongoDB 4.4, so it has to run
+// before the first entry is added.
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	err := s.collection.Database().CreateCollection(ctx, s.collection.Name())
+	var commandError mongo.CommandError
+	if err != nil && !(errors.As(err, &commandError) && commandError.Code == namespaceExists) {
+		return apperrors.InternalServerError("Failed to create outbox collection", err)
+	}
+	_, err = s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+	})
+	if err != nil {
+		return apperrors.InternalServerError("Failed to create outbox index", err)
+	}
+	return nil
+}
+
+func (s *MongoStore) Add(ctx context.Context, entries ...*Entry) error {
+	documents := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		documents = append(documents, entry)
+	}
+	if _, err := s.collection.InsertMany(ctx, documents); err != nil {
+		return apperrors.InternalServerError("Failed to add outbox entries", err)
+	}
+	return nil
+}
+
+func (s *MongoStore) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Entry, error) {
+	filter := bson.M{
+		"status":        StatusPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"nextAttemptAt": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var entry Entry
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, apperrors.InternalServerError("Failed to claim outbox entry", err)
+	}
+	return &entry, nil
+}
+
+func (s *MongoStore) MarkPublished(ctx context.Context, id string, now time.Time) error {
+	return s.update(ctx, id, bson.M{
+		"status":    StatusPublished,
+		"published": now,
+	})
+}
+
+func (s *MongoStore) MarkFailed(ctx context.Context, id string, cause error, nextAttemptAt time.Time) error {
+	return s.update(ctx, id, bson.M{
+		"nextAttemptAt": nextAttemptAt,
+		"lastError":     errorMessage(cause),
+	})
+}
+
+func (s *MongoStore) MarkDead(ctx context.Context, id string, cause error) error {
+	return s.update(ctx, id, bson.M{
+		"status":    StatusDead,
+		"lastError": errorMessage(cause),
+	})
+}
+
+func (s *MongoStore) update(ctx context.Context, id string, fields bson.M) error {
+	if _, err := s.collection.UpdateByID(ctx, id, bson.M{"$set": fields}); err != nil {
+		return apperrors.InternalServerError("Failed to update outbox entry "+id, err)
+	}
+	return nil
+}
+
+func errorMessage(cause error) string {
+	message := cause.Error()
+	if len(message) > MaxErrorLength {
+		return message[:MaxErrorLength]
+	}
+	return message
+}
@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"common/messaging"
+	"context"
+)
+
+// Publisher is a messaging.Publisher which adds events to the outbox instead of delivering them,
+// they are delivered later by a Relay. Publish has to be called within the transaction of the
+// change which caused the events.
+type Publisher struct {
+	store Store
+}
+
+func NewPublisher(store Store) *Publisher {
+	return &Publisher{
+		store: store,
+	}
+}
+
+func (p *Publisher) Publish(ctx context.Context, events ...messaging.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	entries := make([]*Entry, 0, len(events))
+	for _, event := range events {
+		entry, err := NewEntry(ctx, event)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	return p.store.Add(ctx, entries...)
+}
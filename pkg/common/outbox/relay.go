@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"common/logging"
+	"common/messaging"
+	"context"
+	"time"
+)
+
+type RelayOptions struct {
+	// BatchSize is the most entries published by one RunOnce
+	BatchSize int
+	// MaxAttempts is the number of attempts after which an entry is marked dead
+	MaxAttempts int
+	// InitialBackoff is the delay after the first failed attempt, it doubles with every further one
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Lease hides a claimed entry from other relays, it must be longer than publishing takes
+	Lease time.Duration
+}
+
+func DefaultRelayOptions() RelayOptions {
+	return RelayOptions{
+		BatchSize:      100,
+		MaxAttempts:    10,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Minute,
+		Lease:          time.Minute,
+	}
+}
+
+// RelayResult counts what happened to the entries claimed by RunOnce
+type RelayResult struct {
+	Published    int `json:"published"`
+	Failed       int `json:"failed"`
+	DeadLettered int `json:"deadLettered"`
+}
+
+// Relay publishes outbox entries at least once. An entry is published again when the relay stops
+// between publishing it and marking it published, consumers drop duplicates by the event id.
+type Relay struct {
+	store     Store
+	publisher messaging.Publisher
+	options   RelayOptions
+}
+
+func NewRelay(store Store, publisher messaging.Publisher, options RelayOptions) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		options:   options,
+	}
+}
+
+// RunOnce publishes up to BatchSize due entries
+func (r *Relay) RunOnce(ctx context.Context) (RelayResult, error) {
+	var result RelayResult
+	for i := 0; i < r.options.BatchSize; i++ {
+		entry, err := r.store.Claim(ctx, time.Now(), r.options.Lease)
+		if err != nil {
+			return result, err
+		}
+		if entry == nil {
+			break
+		}
+		if err := r.relay(ctx, entry, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Run calls RunOnce every interval until ctx is done, a full batch is followed by the next one
+// without waiting
+func (r *Relay) Run(ctx context.Context, interval time.Duration) error {
+	logger := logging.Log(ctx, "OutboxRelay")
+	for {
+		result, err := r.RunOnce(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Outbox relay run failed")
+		} else if result != (RelayResult{}) {
+			logger.WithField("result", result).Info("Outbox relay run finished")
+		}
+
+		wait := interval
+		if err == nil && result.Published+result.Failed+result.DeadLettered == r.options.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *Relay) relay(ctx context.Context, entry *Entry, result *RelayResult) error {
+	entryCtx := logging.AddTraceToContext(ctx, entry.TraceId)
+	logger := logging.Log(entryCtx, "OutboxRelay").WithField("entryId", entry.Id).WithField("eventType", entry.EventType)
+
+	publishErr := r.publisher.Publish(entryCtx, entry.RawEvent())
+	if publishErr == nil {
+		result.Published++
+		return r.store.MarkPublished(ctx, entry.Id, time.Now())
+	}
+
+	if entry.Attempts >= r.options.MaxAttempts {
+		logger.WithError(publishErr).Errorf("Outbox entry failed %d times, it is marked dead", entry.Attempts)
+		result.DeadLettered++
+		return r.store.MarkDead(ctx, entry.Id, publishErr)
+	}
+	nextAttemptAt := time.Now().Add(r.backoff(entry.Attempts))
+	logger.WithError(publishErr).Warnf("Outbox entry attempt %d failed, it is retried at %s", entry.Attempts, nextAttemptAt.Format(time.RFC3339))
+	result.Failed++
+	return r.store.MarkFailed(ctx, entry.Id, publishErr, nextAttemptAt)
+}
+
+// backoff returns InitialBackoff doubled for every attempt after the first one, at most MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.options.InitialBackoff
+	for i := 1; i < attempts && backoff < r.options.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.options.MaxBackoff {
+		return r.options.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"common/messaging"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryStore is a Store keeping the entries in insertion order
+type memoryStore struct {
+	entries []*Entry
+}
+
+func (s *memoryStore) Add(ctx context.Context, entries ...*Entry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *memoryStore) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Entry, error) {
+	for _, entry := range s.entries {
+		if entry.Status == StatusPending && !entry.NextAttemptAt.After(now) {
+			entry.Attempts++
+			entry.NextAttemptAt = now.Add(lease)
+			claimed := *entry
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) MarkPublished(ctx context.Context, id string, now time.Time) error {
+	entry := s.get(id)
+	entry.Status = StatusPublished
+	entry.Published = &now
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(ctx context.Context, id string, cause error, nextAttemptAt time.Time) error {
+	entry := s.get(id)
+	entry.LastError = cause.Error()
+	entry.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (s *memoryStore) MarkDead(ctx context.Context, id string, cause error) error {
+	entry := s.get(id)
+	entry.Status = StatusDead
+	entry.LastError = cause.Error()
+	return nil
+}
+
+func (s *memoryStore) get(id string) *Entry {
+	for _, entry := range s.entries {
+		if entry.Id == id {
+			return entry
+		}
+	}
+	panic("unknown outbox entry " + id)
+}
+
+type testEvent struct {
+	Number int `json:"number"`
+}
+
+func (testEvent) EventType() string {
+	return "test.created"
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := NewRelay(&memoryStore{}, messaging.NewFakePublisher("test"), RelayOptions{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second})
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+	for _, test := range tests {
+		if got := relay.backoff(test.attempts); got != test.want {
+			t.Errorf("backoff(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestRelayRunOnce(t *testing.T) {
+	options := RelayOptions{BatchSize: 10, MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, Lease: time.Minute}
+	publishErr := errors.New("throttled")
+
+	tests := []struct {
+		name       string
+		entries    int
+		attempts   int
+		batchSize  int
+		publishErr error
+		want       RelayResult
+		wantStatus Status
+		// wantBackoff is the expected delay of the next attempt of failed entries
+		wantBackoff time.Duration
+	}{
+		{name: "published", entries: 2, want: RelayResult{Published: 2}, wantStatus: StatusPublished},
+		{name: "batch size", entries: 3, batchSize: 2, want: RelayResult{Published: 2}},
+		{name: "first failure", entries: 1, publishErr: publishErr, want: RelayResult{Failed: 1}, wantStatus: StatusPending, wantBackoff: time.Minute},
+		{name: "second failure backs off longer", entries: 1, attempts: 1, publishErr: publishErr, want: RelayResult{Failed: 1},
+			wantStatus: StatusPending, wantBackoff: 2 * time.Minute},
+		{name: "last attempt is marked dead", entries: 1, attempts: 2, publishErr: publishErr, want: RelayResult{DeadLettered: 1}, wantStatus: StatusDead},
+		{name: "nothing due", want: RelayResult{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := &memoryStore{}
+			for i := 0; i < test.entries; i++ {
+				entry, err := NewEntry(ctx, testEvent{Number: i})
+				if err != nil {
+					t.Fatal(err)
+				}
+				entry.Attempts = test.attempts
+				_ = store.Add(ctx, entry)
+			}
+			publisher := messaging.NewFakePublisher("test")
+			publisher.FailWith(test.publishErr)
+			relayOptions := options
+			if test.batchSize != 0 {
+				relayOptions.BatchSize = test.batchSize
+			}
+
+			started := time.Now()
+			result, err := NewRelay(store, publisher, relayOptions).RunOnce(ctx)
+			if err != nil {
+				t.Fatalf("RunOnce() error = %v", err)
+			}
+			if result != test.want {
+				t.Errorf("RunOnce() = %+v, want %+v", result, test.want)
+			}
+			if len(publisher.Messages()) != test.want.Published {
+				t.Errorf("published %d messages, want %d", len(publisher.Messages()), test.want.Published)
+			}
+			if test.wantStatus == "" {
+				return
+			}
+			for _, entry := range store.entries {
+				if entry.Status != test.wantStatus {
+					t.Errorf("entry status = %s, want %s", entry.Status, test.wantStatus)
+				}
+				if test.publishErr != nil && entry.LastError != test.publishErr.Error() {
+					t.Errorf("entry last error = %q, want %q", entry.LastError, test.publishErr)
+				}
+				if test.wantBackoff != 0 {
+					if delay := entry.NextAttemptAt.Sub(started); delay < test.wantBackoff || delay > test.wantBackoff+time.Second {
+						t.Errorf("next attempt in %s, want %s", delay, test.wantBackoff)
+					}
+				}
+			}
+		})
+	}
+}
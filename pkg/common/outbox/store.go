@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Store persists outbox entries. Add must take part in the transaction of ctx, so that entries are
+// stored if and only if the change which caused them is.
+type Store interface {
+	Add(ctx context.Context, entries ...*Entry) error
+	// Claim returns a pending entry due at now and hides it from other relays until now+lease,
+	// nil is returned when there is none
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*Entry, error)
+	MarkPublished(ctx context.Context, id string, now time.Time) error
+	// MarkFailed records the failure, the entry is retried at nextAttemptAt
+	MarkFailed(ctx context.Context, id string, cause error, nextAttemptAt time.Time) error
+	// MarkDead records the failure, the entry is not retried any more
+	MarkDead(ctx context.Context, id string, cause error) error
+}
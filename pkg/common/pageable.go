@@ -1,6 +1,8 @@
 package common
 
 import (
+	apperrors "common/errors"
+	"fmt"
 	"math"
 	"strconv"
 )
@@ -19,29 +21,46 @@ type PageFilter struct {
 	// in: query
 	// required: false
 	// example: 20
-	PageSize int64 `json:"pageSize"`
+	PageSize int64 `json:"pageSize" validate:"min=1"`
 
 	// Page number to retrieve
 	//
 	// in: query
 	// required: false
 	// example: 1
-	Page int64 `json:"page"`
+	Page int64 `json:"page" validate:"min=1"`
 
-	// Sort by a specific field
+	// Comma separated fields to sort by, each with an optional direction. The fields which may be
+	// sorted by depend on the endpoint.
 	//
 	// in: query
 	// required: false
-	// example: name
-	SortField string `json:"sortField"`
+	// example: created:desc,name:asc
+	Sort []SortOrder `json:"sort"`
 
-	// Sort order, either asc or desc
+	// Whether total and totalPage are returned, skipping them avoids counting all matching items
 	//
 	// in: query
 	// required: false
-	// enum: ["asc", "desc"]
-	// default: "asc"
-	SortType SortType `json:"sortType"`
+	// default: true
+	IncludeTotal bool `json:"includeTotal"`
+}
+
+const (
+	DefaultPageSize    int64 = 10
+	DefaultMaxPageSize int64 = 100
+)
+
+// PageFilterOptions describes the paging and sorting an endpoint supports
+type PageFilterOptions struct {
+	// SortableFields maps API field names accepted in the sort query parameter to storage fields
+	SortableFields map[string]string
+	// DefaultSort is used when no sort is requested, IdField ascending when empty
+	DefaultSort []SortOrder
+	// DefaultPageSize is used when no pageSize is requested, the package DefaultPageSize when 0
+	DefaultPageSize int64
+	// MaxPageSize is the largest accepted pageSize, the package DefaultMaxPageSize when 0
+	MaxPageSize int64
 }
 
 type Paginated[D any] struct {
@@ -56,59 +75,108 @@ func NewPaginated[D any](data []*D, total int64, pageSize int64, page int64) *Pa
 	}
 }
 
+// NewPaginatedWithoutTotal builds a page for a PageFilter which does not include the total
+func NewPaginatedWithoutTotal[D any](data []*D, pageSize int64, page int64) *Paginated[D] {
+	return &Paginated[D]{
+		Data: data,
+		Pagination: &PaginationData{
+			Number:   page,
+			PageSize: pageSize,
+		},
+	}
+}
+
 type PaginationData struct {
-	Total     int64 `json:"total"`
-	Number    int64 `json:"number"`
-	PageSize  int64 `json:"pageSize"`
-	TotalPage int64 `json:"totalPage"`
+	Total     *int64 `json:"total,omitempty"`
+	Number    int64  `json:"number"`
+	PageSize  int64  `json:"pageSize"`
+	TotalPage *int64 `json:"totalPage,omitempty"`
 }
 
 func NewPaginationData(total int64, pageSize int64, pageNumber int64) *PaginationData {
+	totalPage := int64(math.Ceil(float64(total) / float64(pageSize)))
 	return &PaginationData{
-		Total:     total,
+		Total:     &total,
 		Number:    pageNumber,
 		PageSize:  pageSize,
-		TotalPage: int64(math.Ceil(float64(total) / float64(pageSize))),
+		TotalPage: &totalPage,
 	}
 }
 
-func ParsePageFilter(queryParams map[string]string) *PageFilter {
-	pageSizeString := queryParams["pageSize"]
-	pageSize := int64(0)
-	if pageSizeString != "" {
-		pageSize, _ = strconv.ParseInt(pageSizeString, 10, 64)
+// ParsePageFilter parses pageSize, page, includeTotal and sort query parameters. The sort direction
+// of fields given without one is taken from sortType, which defaults to asc.
+func ParsePageFilter(queryParams map[string]string, options PageFilterOptions) (*PageFilter, error) {
+	pageSize := options.DefaultPageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	maxPageSize := options.MaxPageSize
+	if maxPageSize == 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+	if pageSizeString := queryParams["pageSize"]; pageSizeString != "" {
+		var err error
+		if pageSize, err = parseIntQueryParam(pageSizeString, "pageSize", 1, maxPageSize); err != nil {
+			return nil, err
+		}
 	}
 
-	pageString := queryParams["page"]
-	page := int64(0)
-	if pageString != "" {
-		page, _ = strconv.ParseInt(pageString, 10, 64)
+	page := int64(1)
+	if pageString := queryParams["page"]; pageString != "" {
+		var err error
+		if page, err = parseIntQueryParam(pageString, "page", 1, math.MaxInt64/pageSize); err != nil {
+			return nil, err
+		}
 	}
 
-	sort := queryParams["sort"]
-	if sort == "" {
-		sort = "_id"
+	includeTotal := true
+	if includeTotalString := queryParams["includeTotal"]; includeTotalString != "" {
+		var err error
+		if includeTotal, err = strconv.ParseBool(includeTotalString); err != nil {
+			return nil, apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Query parameter includeTotal '%s' is not a boolean", includeTotalString), "includeTotal", "boolean", err)
+		}
 	}
 
-	sortTypeString := queryParams["sortType"]
 	sortType := SortAsc
-	if sortTypeString == "desc" {
-		sortType = SortDesc
+	if sortTypeString := queryParams["sortType"]; sortTypeString != "" {
+		var err error
+		if sortType, err = ParseSortType(sortTypeString, "sortType"); err != nil {
+			return nil, err
+		}
 	}
 
-	if pageSize < 1 {
-		pageSize = 10
+	sort := options.DefaultSort
+	if len(sort) == 0 {
+		sort = []SortOrder{{Field: IdField, Type: SortAsc}}
 	}
-	if page < 1 {
-		page = 1
+	if sortString := queryParams["sort"]; sortString != "" {
+		var err error
+		if sort, err = ParseSort(sortString, options.SortableFields, sortType); err != nil {
+			return nil, err
+		}
 	}
 
 	return &PageFilter{
-		PageSize:  pageSize,
-		Page:      page,
-		SortField: sort,
-		SortType:  sortType,
+		PageSize:     pageSize,
+		Page:         page,
+		Sort:         sort,
+		IncludeTotal: includeTotal,
+	}, nil
+}
+
+// parseIntQueryParam parses an integer query parameter which has to be within min and max
+func parseIntQueryParam(value string, param string, min int64, max int64) (int64, error) {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("Query parameter %s '%s' is not an integer", param, value), param, "integer", err)
 	}
+	if parsed < min || parsed > max {
+		return 0, apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("Query parameter %s %d is not between %d and %d", param, parsed, min, max), param, fmt.Sprintf("between %d and %d", min, max), nil)
+	}
+	return parsed, nil
 }
 
 func (p PageFilter) GetLimit() int64 {
@@ -121,11 +189,3 @@ func (p PageFilter) GetSkip() int64 {
 	}
 	return p.PageSize * (p.Page - 1)
 }
-
-func (p PageFilter) GetSortTypeInt() int {
-	sortType := 1
-	if p.SortType == "desc" {
-		sortType = -1
-	}
-	return sortType
-}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParsePageFilter(t *testing.T) {
+	idAsc := []SortOrder{{Field: IdField, Type: SortAsc}}
+	options := PageFilterOptions{SortableFields: map[string]string{"name": "name", "created": "created"}}
+
+	tests := []struct {
+		name    string
+		query   map[string]string
+		options PageFilterOptions
+		want    *PageFilter
+		wantErr bool
+	}{
+		{name: "defaults", options: options, want: &PageFilter{PageSize: DefaultPageSize, Page: 1, Sort: idAsc, IncludeTotal: true}},
+		{name: "endpoint defaults", options: PageFilterOptions{DefaultPageSize: 25, DefaultSort: []SortOrder{{Field: "created", Type: SortDesc}}},
+			want: &PageFilter{PageSize: 25, Page: 1, Sort: []SortOrder{{Field: "created", Type: SortDesc}}, IncludeTotal: true}},
+		{name: "page and size", query: map[string]string{"pageSize": "50", "page": "3"}, options: options,
+			want: &PageFilter{PageSize: 50, Page: 3, Sort: idAsc, IncludeTotal: true}},
+		{name: "largest page size", query: map[string]string{"pageSize": "100"}, options: options,
+			want: &PageFilter{PageSize: DefaultMaxPageSize, Page: 1, Sort: idAsc, IncludeTotal: true}},
+		{name: "page size above maximum", query: map[string]string{"pageSize": "101"}, options: options, wantErr: true},
+		{name: "endpoint maximum", query: map[string]string{"pageSize": "30"}, options: PageFilterOptions{MaxPageSize: 20}, wantErr: true},
+		{name: "zero page size", query: map[string]string{"pageSize": "0"}, options: options, wantErr: true},
+		{name: "negative page", query: map[string]string{"page": "-1"}, options: options, wantErr: true},
+		{name: "zero page", query: map[string]string{"page": "0"}, options: options, wantErr: true},
+		{name: "page not an integer", query: map[string]string{"page": "two"}, options: options, wantErr: true},
+		{name: "page overflowing the limit", query: map[string]string{"pageSize": "10", "page": strconv.FormatInt(math.MaxInt64/10+1, 10)}, options: options, wantErr: true},
+		{name: "last page within the limit", query: map[string]string{"pageSize": "10", "page": strconv.FormatInt(math.MaxInt64/10, 10)}, options: options,
+			want: &PageFilter{PageSize: 10, Page: math.MaxInt64 / 10, Sort: idAsc, IncludeTotal: true}},
+		{name: "page beyond int64", query: map[string]string{"page": "9223372036854775808"}, options: options, wantErr: true},
+		{name: "without total", query: map[string]string{"includeTotal": "false"}, options: options,
+			want: &PageFilter{PageSize: DefaultPageSize, Page: 1, Sort: idAsc, IncludeTotal: false}},
+		{name: "include total not a boolean", query: map[string]string{"includeTotal": "maybe"}, options: options, wantErr: true},
+		{name: "sort with sort type", query: map[string]string{"sort": "name,created:asc", "sortType": "desc"}, options: options,
+			want: &PageFilter{PageSize: DefaultPageSize, Page: 1, Sort: []SortOrder{{Field: "name", Type: SortDesc}, {Field: "created", Type: SortAsc}}, IncludeTotal: true}},
+		{name: "unknown sort type", query: map[string]string{"sortType": "up"}, options: options, wantErr: true},
+		{name: "unknown sort field", query: map[string]string{"sort": "total"}, options: options, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParsePageFilter(test.query, test.options)
+			if test.wantErr {
+				if !apperrors.Is(err, apperrors.INVALID_REQUEST_PARAMETERS) {
+					t.Fatalf("ParsePageFilter() error = %v, want INVALID_REQUEST_PARAMETERS", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePageFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParsePageFilter() = %+v, want %+v", got, test.want)
+			}
+			if got.GetLimit() < got.PageSize {
+				t.Errorf("GetLimit() = %d overflows", got.GetLimit())
+			}
+		})
+	}
+}
+
+func TestPageFilterBounds(t *testing.T) {
+	tests := []struct {
+		filter    PageFilter
+		wantSkip  int64
+		wantLimit int64
+	}{
+		{PageFilter{PageSize: 10, Page: 1}, 0, 10},
+		{PageFilter{PageSize: 10, Page: 3}, 20, 30},
+		{PageFilter{PageSize: 10}, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(strconv.FormatInt(test.filter.Page, 10), func(t *testing.T) {
+			if skip := test.filter.GetSkip(); skip != test.wantSkip {
+				t.Errorf("GetSkip() = %d, want %d", skip, test.wantSkip)
+			}
+			if limit := test.filter.GetLimit(); limit != test.wantLimit {
+				t.Errorf("GetLimit() = %d, want %d", limit, test.wantLimit)
+			}
+		})
+	}
+}
+
+func TestNewPaginationData(t *testing.T) {
+	tests := []struct {
+		total         int64
+		wantTotalPage int64
+	}{
+		{0, 0},
+		{1, 1},
+		{10, 1},
+		{11, 2},
+	}
+	for _, test := range tests {
+		t.Run(strconv.FormatInt(test.total, 10), func(t *testing.T) {
+			if got := NewPaginationData(test.total, 10, 1); *got.TotalPage != test.wantTotalPage {
+				t.Errorf("TotalPage = %d, want %d", *got.TotalPage, test.wantTotalPage)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrorFormat selects how SerializeError writes error responses
+type ErrorFormat string
+
+const (
+	// ErrorFormatLegacy writes an ErrorResponseDto as application/json
+	ErrorFormatLegacy ErrorFormat = "legacy"
+	// ErrorFormatProblem writes RFC 7807 ProblemDetails as application/problem+json
+	ErrorFormatProblem ErrorFormat = "problem"
+)
+
+const (
+	AcceptHeader           = "Accept"
+	ProblemJsonContentType = "application/problem+json"
+	// DefaultProblemTypeBaseUri prefixes the error code in the type of problem details, e.g.
+	// urn:problem-type:entity-not-found
+	DefaultProblemTypeBaseUri = "urn:problem-type:"
+)
+
+// ProblemDetails is an RFC 7807 error response. The error code, trace, params and violations of the
+// legacy format are kept as extension members.
+type ProblemDetails struct {
+	Type       string                     `json:"type"`
+	Title      string                     `json:"title"`
+	Status     int                        `json:"status"`
+	Detail     string                     `json:"detail,omitempty"`
+	Instance   string                     `json:"instance,omitempty"`
+	ErrorCode  string                     `json:"errorCode"`
+	TraceId    string                     `json:"traceId"`
+	SpanId     string                     `json:"spanId"`
+	Params     map[string]string          `json:"params,omitempty"`
+	Violations []apperrors.FieldViolation `json:"violations,omitempty"`
+}
+
+// ErrorResponseOptions configure the error responses of a service
+type ErrorResponseOptions struct {
+	// Format is used unless the client asks for problem details in its Accept header
+	Format             ErrorFormat
+	ProblemTypeBaseUri string
+}
+
+var (
+	errorResponseMu      sync.RWMutex
+	errorResponseOptions = ErrorResponseOptions{
+		Format:             ErrorFormatLegacy,
+		ProblemTypeBaseUri: DefaultProblemTypeBaseUri,
+	}
+)
+
+// ConfigureErrorResponses sets the error format of the service, services call it once at startup
+func ConfigureErrorResponses(options ErrorResponseOptions) error {
+	format, err := ParseErrorFormat(string(options.Format))
+	if err != nil {
+		return err
+	}
+	options.Format = format
+	if options.ProblemTypeBaseUri == "" {
+		options.ProblemTypeBaseUri = DefaultProblemTypeBaseUri
+	}
+	errorResponseMu.Lock()
+	defer errorResponseMu.Unlock()
+	errorResponseOptions = options
+	return nil
+}
+
+// ParseErrorFormat parses legacy or problem, an empty value is legacy
+func ParseErrorFormat(value string) (ErrorFormat, error) {
+	switch ErrorFormat(strings.ToLower(strings.TrimSpace(value))) {
+	case "", ErrorFormatLegacy:
+		return ErrorFormatLegacy, nil
+	case ErrorFormatProblem:
+		return ErrorFormatProblem, nil
+	}
+	return "", fmt.Errorf("unsupported error format '%s', expected %s or %s", value, ErrorFormatLegacy, ErrorFormatProblem)
+}
+
+func getErrorResponseOptions() ErrorResponseOptions {
+	errorResponseMu.RLock()
+	defer errorResponseMu.RUnlock()
+	return errorResponseOptions
+}
+
+type errorFormatContextKey struct{}
+
+type errorFormatContext struct {
+	format   ErrorFormat
+	instance string
+}
+
+// ErrorFormatNegotiation selects problem details for clients accepting application/problem+json,
+// other clients get the format of the service. The request path becomes the problem instance.
+func ErrorFormatNegotiation() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			format := getErrorResponseOptions().Format
+			if acceptsProblemJson(GetHeader(request, AcceptHeader)) {
+				format = ErrorFormatProblem
+			}
+			ctx = context.WithValue(ctx, errorFormatContextKey{}, errorFormatContext{format: format, instance: request.Path})
+			return next(ctx, request)
+		}
+	}
+}
+
+// WithErrorFormat returns a context in which SerializeError writes errors in format
+func WithErrorFormat(ctx context.Context, format ErrorFormat) context.Context {
+	formatContext, _ := ctx.Value(errorFormatContextKey{}).(errorFormatContext)
+	formatContext.format = format
+	return context.WithValue(ctx, errorFormatContextKey{}, formatContext)
+}
+
+// GetErrorFormat returns the error format selected for the request in ctx or the format of the service
+func GetErrorFormat(ctx context.Context) ErrorFormat {
+	if formatContext, ok := ctx.Value(errorFormatContextKey{}).(errorFormatContext); ok && formatContext.format != "" {
+		return formatContext.format
+	}
+	return getErrorResponseOptions().Format
+}
+
+func getProblemInstance(ctx context.Context) string {
+	formatContext, _ := ctx.Value(errorFormatContextKey{}).(errorFormatContext)
+	return formatContext.instance
+}
+
+// acceptsProblemJson reports whether the Accept header prefers application/problem+json to
+// application/json by their q-values. A tie goes to problem details, which a client only lists on
+// purpose, wildcards match both types alike and do not change the preference.
+func acceptsProblemJson(accept string) bool {
+	problemQuality, jsonQuality := -1.0, -1.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, parameters, _ := strings.Cut(mediaRange, ";")
+		quality := mediaRangeQuality(parameters)
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case ProblemJsonContentType:
+			problemQuality = max(problemQuality, quality)
+		case "application/json":
+			jsonQuality = max(jsonQuality, quality)
+		}
+	}
+	return problemQuality > 0 && problemQuality >= jsonQuality
+}
+
+// mediaRangeQuality returns the q parameter of a media range, 1 when it is missing or invalid
+func mediaRangeQuality(parameters string) float64 {
+	for _, parameter := range strings.Split(parameters, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(parameter), "=")
+		if strings.TrimSpace(name) != "q" {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 1
+		}
+		return quality
+	}
+	return 1
+}
+
+// newProblemDetails maps an error response into problem details. Detail lists the violation
+// messages, other client errors are detailed by the message of the error. Server errors are
+// detailed by their description only, their internal description is never exposed.
+func newProblemDetails(ctx context.Context, errorDto ErrorResponseDto, status int, err error) ProblemDetails {
+	details := make([]string, 0, len(errorDto.Violations))
+	for _, violation := range errorDto.Violations {
+		details = append(details, violation.Field+": "+violation.Message)
+	}
+	detail := strings.Join(details, "; ")
+	if detail == "" {
+		detail = errorDto.Description
+		if appError, ok := apperrors.As(err); ok && status < http.StatusInternalServerError && appError.InternalDescription != "" {
+			detail = appError.InternalDescription
+		}
+	}
+	return ProblemDetails{
+		Type:       problemType(errorDto.ErrorCode),
+		Title:      errorDto.Description,
+		Status:     status,
+		Detail:     detail,
+		Instance:   getProblemInstance(ctx),
+		ErrorCode:  errorDto.ErrorCode,
+		TraceId:    errorDto.TraceId,
+		SpanId:     errorDto.SpanId,
+		Params:     errorDto.Params,
+		Violations: errorDto.Violations,
+	}
+}
+
+func problemType(errorCode string) string {
+	return getErrorResponseOptions().ProblemTypeBaseUri + strings.ToLower(strings.ReplaceAll(errorCode, "_", "-"))
+}
@@ -0,0 +1,185 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAcceptsProblemJson(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"*/*", false},
+		{"application/problem+json", true},
+		{"Application/Problem+JSON", true},
+		{"application/json, application/problem+json", true},
+		{" application/problem+json ; charset=utf-8", true},
+		{"application/problem+json;q=0.5", true},
+		{"application/problem+json;q=1", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json; q=0.000", false},
+		{"application/problem+xml", false},
+		{"application/json;q=1, application/problem+json;q=0.1", false},
+		{"application/json;q=0.5, application/problem+json", true},
+		{"application/problem+json;q=0.8, application/json;q=0.8", true},
+		{"application/problem+json, */*;q=0.1", true},
+		{"application/problem+json;q=abc", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := acceptsProblemJson(tt.accept); got != tt.want {
+				t.Errorf("acceptsProblemJson(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ErrorFormat
+		wantErr bool
+	}{
+		{"", ErrorFormatLegacy, false},
+		{"legacy", ErrorFormatLegacy, false},
+		{" Problem ", ErrorFormatProblem, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseErrorFormat(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseErrorFormat() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseErrorFormat() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializeErrorFormats(t *testing.T) {
+	notFound := apperrors.EntityNotFound("Order order-1 not found", "id", "order-1", nil)
+	invalid := apperrors.InvalidRequestParameters("invalid", []apperrors.FieldViolation{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "items", Rule: "minLength=1", Message: "must have at least 1 elements"},
+	})
+
+	tests := []struct {
+		name            string
+		serviceFormat   ErrorFormat
+		accept          string
+		err             error
+		wantStatus      int
+		wantContentType string
+		wantBody        map[string]any
+	}{
+		{
+			name:            "legacy",
+			serviceFormat:   ErrorFormatLegacy,
+			accept:          "application/json",
+			err:             notFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json",
+			wantBody: map[string]any{
+				"errorCode": "ENTITY_NOT_FOUND", "description": notFound.Description, "traceId": "", "spanId": "",
+				"params": map[string]any{"id": "order-1"},
+			},
+		},
+		{
+			name:            "problem negotiated by accept header",
+			serviceFormat:   ErrorFormatLegacy,
+			accept:          "application/problem+json",
+			err:             notFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: ProblemJsonContentType,
+			wantBody: map[string]any{
+				"type": "urn:problem-type:entity-not-found", "title": notFound.Description, "status": float64(http.StatusNotFound),
+				"detail": "Order order-1 not found", "instance": "/orders/order-1", "errorCode": "ENTITY_NOT_FOUND", "traceId": "", "spanId": "",
+				"params": map[string]any{"id": "order-1"},
+			},
+		},
+		{
+			name:            "problem format of the service lists violations",
+			serviceFormat:   ErrorFormatProblem,
+			err:             invalid,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: ProblemJsonContentType,
+			wantBody: map[string]any{
+				"type": "urn:problem-type:invalid-request-parameters", "title": invalid.Description, "status": float64(http.StatusBadRequest),
+				"detail": "name: is required; items: must have at least 1 elements", "instance": "/orders/order-1",
+				"errorCode": "INVALID_REQUEST_PARAMETERS", "traceId": "", "spanId": "",
+				"params": map[string]any{"param": "name", "rule": "required"},
+				"violations": []any{
+					map[string]any{"field": "name", "rule": "required", "message": "is required"},
+					map[string]any{"field": "items", "rule": "minLength=1", "message": "must have at least 1 elements"},
+				},
+			},
+		},
+		{
+			name:            "internal details are not exposed",
+			serviceFormat:   ErrorFormatProblem,
+			err:             errors.New("connection refused"),
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: ProblemJsonContentType,
+			wantBody: map[string]any{
+				"type": "urn:problem-type:internal-server-error", "title": "Internal server error has occurred",
+				"status": float64(http.StatusInternalServerError), "detail": "Internal server error has occurred", "instance": "/orders/order-1",
+				"errorCode": "INTERNAL_SERVER_ERROR", "traceId": "", "spanId": "",
+			},
+		},
+		{
+			name:            "internal description of server errors is not exposed",
+			serviceFormat:   ErrorFormatProblem,
+			err:             apperrors.InternalServerError("Mongo at db-1 timed out", nil),
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: ProblemJsonContentType,
+			wantBody: map[string]any{
+				"type": "urn:problem-type:internal-server-error", "title": "Internal server error has occurred",
+				"status": float64(http.StatusInternalServerError), "detail": "Internal server error has occurred", "instance": "/orders/order-1",
+				"errorCode": "INTERNAL_SERVER_ERROR", "traceId": "", "spanId": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ConfigureErrorResponses(ErrorResponseOptions{Format: tt.serviceFormat}); err != nil {
+				t.Fatalf("ConfigureErrorResponses() error = %v", err)
+			}
+			t.Cleanup(func() { _ = ConfigureErrorResponses(ErrorResponseOptions{}) })
+
+			handler := ErrorFormatNegotiation()(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return SerializeError(ctx, tt.err)
+			})
+			response, err := handler(context.Background(), events.APIGatewayProxyRequest{
+				Path:    "/orders/order-1",
+				Headers: map[string]string{"accept": tt.accept},
+			})
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if contentType := response.Headers["Content-Type"]; contentType != tt.wantContentType {
+				t.Errorf("Content-Type = %s, want %s", contentType, tt.wantContentType)
+			}
+			var body map[string]any
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("body %s is not JSON: %v", response.Body, err)
+			}
+			if !reflect.DeepEqual(body, tt.wantBody) {
+				t.Errorf("body = %v, want %v", body, tt.wantBody)
+			}
+		})
+	}
+}
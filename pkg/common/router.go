@@ -0,0 +1,167 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HandlerFunc is the signature of an API Gateway proxy Lambda handler.
+type HandlerFunc func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// Router dispatches API Gateway proxy requests to handlers registered by method and path template.
+// Path templates use API Gateway syntax, e.g. "/orders/{orderId}". Extracted path parameters are
+// merged into request.PathParameters before the handler is invoked.
+type Router struct {
+	routes      []*route
+	middlewares []Middleware
+}
+
+type route struct {
+	method   string
+	template string
+	segments []string
+	handler  HandlerFunc
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// AddRoute registers handler for the given HTTP method and path template
+func (r *Router) AddRoute(method string, pathTemplate string, handler HandlerFunc) {
+	r.routes = append(r.routes, &route{
+		method:   strings.ToUpper(method),
+		template: pathTemplate,
+		segments: splitPath(pathTemplate),
+		handler:  handler,
+	})
+}
+
+func (r *Router) Get(pathTemplate string, handler HandlerFunc) {
+	r.AddRoute(http.MethodGet, pathTemplate, handler)
+}
+
+func (r *Router) Post(pathTemplate string, handler HandlerFunc) {
+	r.AddRoute(http.MethodPost, pathTemplate, handler)
+}
+
+func (r *Router) Put(pathTemplate string, handler HandlerFunc) {
+	r.AddRoute(http.MethodPut, pathTemplate, handler)
+}
+
+func (r *Router) Patch(pathTemplate string, handler HandlerFunc) {
+	r.AddRoute(http.MethodPatch, pathTemplate, handler)
+}
+
+func (r *Router) Delete(pathTemplate string, handler HandlerFunc) {
+	r.AddRoute(http.MethodDelete, pathTemplate, handler)
+}
+
+// Use appends middlewares applied to every request handled by the router, including unmatched ones
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Handle is the Lambda entrypoint, it can be passed directly to lambda.Start
+func (r *Router) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return Chain(r.dispatch, r.middlewares...)(ctx, request)
+}
+
+func (r *Router) dispatch(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	path := request.Path
+	if path == "" {
+		path = request.Resource
+	}
+	segments := splitPath(path)
+	method := strings.ToUpper(request.HTTPMethod)
+
+	var allowedMethods []string
+	for _, rt := range r.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		if rt.method != method {
+			allowedMethods = appendUnique(allowedMethods, rt.method)
+			continue
+		}
+		request.PathParameters = mergePathParameters(request.PathParameters, params)
+		return rt.handler(ctx, request)
+	}
+
+	if len(allowedMethods) > 0 {
+		sort.Strings(allowedMethods)
+		response, err := SerializeError(ctx, apperrors.MethodNotAllowed(fmt.Sprintf("Method %s is not allowed for %s", method, path), method))
+		response.Headers["Allow"] = strings.Join(allowedMethods, ", ")
+		return response, err
+	}
+	return SerializeError(ctx, apperrors.ResourceNotFound(fmt.Sprintf("No route found for %s %s", method, path), path))
+}
+
+func (rt *route) match(segments []string) (map[string]string, bool) {
+	params := map[string]string{}
+	for i, templateSegment := range rt.segments {
+		name, isParam := pathParameterName(templateSegment)
+		if isParam && strings.HasSuffix(name, "+") {
+			if i >= len(segments) {
+				return nil, false
+			}
+			params[strings.TrimSuffix(name, "+")] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		if isParam {
+			params[name] = segments[i]
+			continue
+		}
+		if templateSegment != segments[i] {
+			return nil, false
+		}
+	}
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+func pathParameterName(segment string) (string, bool) {
+	if len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+func mergePathParameters(existing map[string]string, extracted map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(extracted))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range extracted {
+		merged[key] = value
+	}
+	return merged
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	handler := func(name string) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			body, _ := json.Marshal(map[string]any{"route": name, "params": request.PathParameters})
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(body)}, nil
+		}
+	}
+	router.Get("/orders", handler("list"))
+	router.Post("/orders", handler("create"))
+	router.Get("/orders/search", handler("search"))
+	router.Get("/orders/{orderId}", handler("get"))
+	router.Delete("/orders/{orderId}", handler("delete"))
+	router.Post("/orders/{orderId}/items/{sku}", handler("item"))
+	router.Get("/files/{path+}", handler("file"))
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		params      map[string]string
+		wantStatus  int
+		wantRoute   string
+		wantParams  map[string]string
+		wantAllow   string
+		wantErrCode string
+	}{
+		{name: "static", method: "GET", path: "/orders", wantStatus: 200, wantRoute: "list", wantParams: map[string]string{}},
+		{name: "method selects route", method: "POST", path: "/orders", wantStatus: 200, wantRoute: "create", wantParams: map[string]string{}},
+		{name: "lower case method", method: "get", path: "/orders", wantStatus: 200, wantRoute: "list", wantParams: map[string]string{}},
+		{name: "trailing slash", method: "GET", path: "/orders/", wantStatus: 200, wantRoute: "list", wantParams: map[string]string{}},
+		{name: "first registered route wins", method: "GET", path: "/orders/search", wantStatus: 200, wantRoute: "search", wantParams: map[string]string{}},
+		{name: "path parameter", method: "GET", path: "/orders/order-1", wantStatus: 200, wantRoute: "get", wantParams: map[string]string{"orderId": "order-1"}},
+		{name: "several parameters", method: "POST", path: "/orders/order-1/items/sku-1", wantStatus: 200, wantRoute: "item",
+			wantParams: map[string]string{"orderId": "order-1", "sku": "sku-1"}},
+		{name: "existing parameters are kept", method: "GET", path: "/orders/order-1", params: map[string]string{"stage": "dev"}, wantStatus: 200,
+			wantRoute: "get", wantParams: map[string]string{"orderId": "order-1", "stage": "dev"}},
+		{name: "greedy parameter", method: "GET", path: "/files/a/b/c.txt", wantStatus: 200, wantRoute: "file", wantParams: map[string]string{"path": "a/b/c.txt"}},
+		{name: "greedy parameter needs a segment", method: "GET", path: "/files", wantStatus: 404, wantErrCode: "RESOURCE_NOT_FOUND"},
+		{name: "method not allowed", method: "PUT", path: "/orders/order-1", wantStatus: 405, wantAllow: "DELETE, GET", wantErrCode: "METHOD_NOT_ALLOWED"},
+		{name: "unknown path", method: "GET", path: "/customers", wantStatus: 404, wantErrCode: "RESOURCE_NOT_FOUND"},
+		{name: "too many segments", method: "GET", path: "/orders/order-1/extra", wantStatus: 404, wantErrCode: "RESOURCE_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := router.Handle(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     tt.method,
+				Path:           tt.path,
+				PathParameters: tt.params,
+			})
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("StatusCode = %d, want %d, body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if allow := response.Headers["Allow"]; allow != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", allow, tt.wantAllow)
+			}
+
+			var body struct {
+				Route     string            `json:"route"`
+				Params    map[string]string `json:"params"`
+				ErrorCode string            `json:"errorCode"`
+			}
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("body %s is not JSON: %v", response.Body, err)
+			}
+			if body.Route != tt.wantRoute || body.ErrorCode != tt.wantErrCode {
+				t.Errorf("route %q error %q, want route %q error %q", body.Route, body.ErrorCode, tt.wantRoute, tt.wantErrCode)
+			}
+			if tt.wantParams != nil && !reflect.DeepEqual(body.Params, tt.wantParams) {
+				t.Errorf("PathParameters = %v, want %v", body.Params, tt.wantParams)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"common/logging"
+	"context"
+	"sync"
+	"time"
+)
+
+// CachingProvider keeps resolved secrets in memory for ttl. Expired secrets are refreshed from the
+// underlying provider, the stale value is returned if the refresh fails so that a temporary outage
+// of the secret store does not fail requests. Concurrent reads of the same secret share one fetch,
+// reads of other secrets are not blocked by it.
+type CachingProvider struct {
+	provider Provider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]*fetch
+}
+
+type cacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// fetch is a pending read of a secret from the underlying provider
+type fetch struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+func NewCachingProvider(provider Provider, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  map[string]cacheEntry{},
+		inflight: map[string]*fetch{},
+	}
+}
+
+func (p *CachingProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	entry, cached := p.entries[name]
+	if cached && p.now().Before(entry.expiresAt) {
+		p.mu.Unlock()
+		return entry.value, nil
+	}
+	pending, fetching := p.inflight[name]
+	if !fetching {
+		pending = &fetch{done: make(chan struct{})}
+		p.inflight[name] = pending
+	}
+	p.mu.Unlock()
+
+	if fetching {
+		select {
+		case <-pending.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	} else {
+		pending.value, pending.err = p.provider.GetSecret(ctx, name)
+		p.mu.Lock()
+		if pending.err == nil {
+			p.entries[name] = cacheEntry{
+				value:     pending.value,
+				expiresAt: p.now().Add(p.ttl),
+			}
+		}
+		delete(p.inflight, name)
+		p.mu.Unlock()
+		close(pending.done)
+	}
+
+	if pending.err != nil {
+		if cached {
+			logging.Log(ctx, "SecretsCache").WithError(pending.err).Warnf("Failed to refresh secret %s, using cached value", name)
+			return entry.value, nil
+		}
+		return "", pending.err
+	}
+	return pending.value, nil
+}
+
+// Invalidate removes the secret from the cache, e.g. after authentication with it failed
+func (p *CachingProvider) Invalidate(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, name)
+}
@@ -0,0 +1,126 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider counts reads and can block or fail them
+type countingProvider struct {
+	reads   atomic.Int32
+	value   atomic.Value
+	err     atomic.Value
+	release chan struct{}
+}
+
+func newCountingProvider(value string) *countingProvider {
+	provider := &countingProvider{}
+	provider.value.Store(value)
+	provider.err.Store(errors.New(""))
+	return provider
+}
+
+func (p *countingProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.reads.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	if err := p.err.Load().(error); err.Error() != "" {
+		return "", err
+	}
+	return p.value.Load().(string), nil
+}
+
+func TestCachingProvider(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := newCountingProvider("v1")
+	cache := NewCachingProvider(provider, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		value     string
+		fail      bool
+		invalid   bool
+		want      string
+		wantReads int32
+	}{
+		{name: "first read fetches", want: "v1", wantReads: 1},
+		{name: "cached within ttl", advance: 30 * time.Second, value: "v2", want: "v1", wantReads: 1},
+		{name: "refreshed after ttl", advance: 31 * time.Second, want: "v2", wantReads: 2},
+		{name: "stale value on failed refresh", advance: 2 * time.Minute, fail: true, want: "v2", wantReads: 3},
+		{name: "invalidate fetches again", invalid: true, value: "v3", want: "v3", wantReads: 4},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.value != "" {
+			provider.value.Store(step.value)
+		}
+		if step.fail {
+			provider.err.Store(errors.New("unavailable"))
+		} else {
+			provider.err.Store(errors.New(""))
+		}
+		if step.invalid {
+			cache.Invalidate("db")
+		}
+		got, err := cache.GetSecret(ctx, "db")
+		if err != nil {
+			t.Fatalf("%s: GetSecret() error = %v", step.name, err)
+		}
+		if got != step.want || provider.reads.Load() != step.wantReads {
+			t.Errorf("%s: GetSecret() = %s after %d reads, want %s after %d", step.name, got, provider.reads.Load(), step.want, step.wantReads)
+		}
+	}
+}
+
+func TestCachingProviderFailsWithoutCachedValue(t *testing.T) {
+	provider := newCountingProvider("v1")
+	provider.err.Store(errors.New("unavailable"))
+	if _, err := NewCachingProvider(provider, time.Minute).GetSecret(context.Background(), "db"); err == nil {
+		t.Fatal("GetSecret() error = nil, want error")
+	}
+}
+
+func TestCachingProviderSharesConcurrentFetches(t *testing.T) {
+	slow := newCountingProvider("slow")
+	slow.release = make(chan struct{})
+	cache := NewCachingProvider(slow, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := cache.GetSecret(context.Background(), "db"); err != nil || got != "slow" {
+				t.Errorf("GetSecret() = %s, %v", got, err)
+			}
+		}()
+	}
+	// other secrets are served while the fetch of db is pending
+	cache.mu.Lock()
+	cache.entries["other"] = cacheEntry{value: "cached", expiresAt: time.Now().Add(time.Minute)}
+	cache.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		cache.GetSecret(context.Background(), "other")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GetSecret of another secret is blocked by a pending fetch")
+	}
+
+	close(slow.release)
+	wg.Wait()
+	if reads := slow.reads.Load(); reads != 1 {
+		t.Errorf("provider read %d times, want 1", reads)
+	}
+}
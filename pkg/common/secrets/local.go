@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	apperrors "common/errors"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// StaticProvider serves secrets from memory, it is meant for tests
+type StaticProvider struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+func NewStaticProvider(secrets map[string]string) *StaticProvider {
+	copied := make(map[string]string, len(secrets))
+	for name, value := range secrets {
+		copied[name] = value
+	}
+	return &StaticProvider{
+		secrets: copied,
+	}
+}
+
+func (p *StaticProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	value, ok := p.secrets[name]
+	if !ok {
+		return "", secretNotFound(name, nil)
+	}
+	return value, nil
+}
+
+// SetSecret adds or replaces a secret, e.g. to simulate rotation
+func (p *StaticProvider) SetSecret(name string, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets[name] = value
+}
+
+// EnvProvider serves secrets from environment variables for local runs. The secret name is converted to
+// a variable name by upper casing it and replacing every character other than a letter or digit with '_',
+// e.g. "order/mongo-credentials" is read from ORDER_MONGO_CREDENTIALS.
+type EnvProvider struct{}
+
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{}
+}
+
+func (p *EnvProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(EnvVariableName(name))
+	if !ok {
+		return "", secretNotFound(name, nil)
+	}
+	return value, nil
+}
+
+// EnvVariableName returns the environment variable EnvProvider reads the secret from
+func EnvVariableName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, name)
+}
+
+// FileProvider serves secrets from files in a directory for local runs, the file name being
+// the secret name with path separators replaced by '_'
+type FileProvider struct {
+	directory string
+}
+
+func NewFileProvider(directory string) *FileProvider {
+	return &FileProvider{
+		directory: directory,
+	}
+}
+
+func (p *FileProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	fileName := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+	content, err := os.ReadFile(filepath.Join(p.directory, fileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", secretNotFound(name, err)
+		}
+		return "", apperrors.InternalServerError(fmt.Sprintf("Failed to read secret %s from file", name), err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	apperrors "common/errors"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ParameterStoreClient is the subset of the SSM API used by ParameterStoreProvider
+type ParameterStoreClient interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// ParameterStoreProvider resolves secrets from SSM Parameter Store, SecureString parameters are decrypted
+type ParameterStoreProvider struct {
+	client ParameterStoreClient
+}
+
+func NewParameterStoreProvider(cfg aws.Config) *ParameterStoreProvider {
+	return NewParameterStoreProviderWithClient(ssm.NewFromConfig(cfg))
+}
+
+func NewParameterStoreProviderWithClient(client ParameterStoreClient) *ParameterStoreProvider {
+	return &ParameterStoreProvider{
+		client: client,
+	}
+}
+
+func (p *ParameterStoreProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	output, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		var notFound *types.ParameterNotFound
+		if errors.As(err, &notFound) {
+			return "", secretNotFound(name, err)
+		}
+		return "", apperrors.InternalServerError(fmt.Sprintf("Failed to get parameter %s from Parameter Store", name), err)
+	}
+	if output.Parameter == nil || output.Parameter.Value == nil {
+		return "", secretNotFound(name, nil)
+	}
+	return *output.Parameter.Value, nil
+}
@@ -0,0 +1,29 @@
+package secrets
+
+import (
+	apperrors "common/errors"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Provider resolves secret values by name, e.g. a Secrets Manager ARN or an SSM parameter name
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// GetJSONSecret resolves the secret and unmarshals its JSON value into target
+func GetJSONSecret(ctx context.Context, provider Provider, name string, target interface{}) error {
+	value, err := provider.GetSecret(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(value), target); err != nil {
+		return apperrors.InternalServerError(fmt.Sprintf("Secret %s is not a valid JSON document", name), err)
+	}
+	return nil
+}
+
+func secretNotFound(name string, cause error) error {
+	return apperrors.EntityNotFound(fmt.Sprintf("Secret %s not found", name), "secret", name, cause)
+}
@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	apperrors "common/errors"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// SecretsManagerClient is the subset of the Secrets Manager API used by SecretsManagerProvider
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManagerProvider resolves secrets from AWS Secrets Manager, name being the secret ARN or name
+type SecretsManagerProvider struct {
+	client SecretsManagerClient
+}
+
+func NewSecretsManagerProvider(cfg aws.Config) *SecretsManagerProvider {
+	return NewSecretsManagerProviderWithClient(secretsmanager.NewFromConfig(cfg))
+}
+
+func NewSecretsManagerProviderWithClient(client SecretsManagerClient) *SecretsManagerProvider {
+	return &SecretsManagerProvider{
+		client: client,
+	}
+}
+
+func (p *SecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	output, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", secretNotFound(name, err)
+		}
+		return "", apperrors.InternalServerError(fmt.Sprintf("Failed to get secret %s from Secrets Manager", name), err)
+	}
+	if output.SecretString != nil {
+		return *output.SecretString, nil
+	}
+	return string(output.SecretBinary), nil
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortOrder is a single sort field and its direction
+type SortOrder struct {
+	Field string   `json:"field"`
+	Type  SortType `json:"type"`
+}
+
+// GetSortTypeInt returns 1 for ascending and -1 for descending order
+func (s SortOrder) GetSortTypeInt() int {
+	if s.Type == SortDesc {
+		return -1
+	}
+	return 1
+}
+
+// ParseSort parses a comma separated list of API field names with an optional direction, e.g.
+// `created:desc,name:asc`, into sort orders of storage fields. sortableFields maps the API names
+// which may be sorted by to their storage fields, defaultType is used for fields without a direction.
+func ParseSort(value string, sortableFields map[string]string, defaultType SortType) ([]SortOrder, error) {
+	var sortOrders []SortOrder
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		name, direction, hasDirection := strings.Cut(strings.TrimSpace(part), ":")
+		field, ok := sortableFields[name]
+		if !ok {
+			return nil, apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Sorting by '%s' is not supported", name), "sort", fmt.Sprintf("field one of %s", joinSortableFields(sortableFields)), nil)
+		}
+		if seen[name] {
+			return nil, apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Sort field '%s' is given more than once", name), "sort", "unique fields", nil)
+		}
+		seen[name] = true
+
+		sortType := defaultType
+		if hasDirection {
+			parsed, err := ParseSortType(direction, "sort")
+			if err != nil {
+				return nil, err
+			}
+			sortType = parsed
+		}
+		sortOrders = append(sortOrders, SortOrder{Field: field, Type: sortType})
+	}
+	return sortOrders, nil
+}
+
+// ParseSortType parses asc or desc given in param
+func ParseSortType(value string, param string) (SortType, error) {
+	switch SortType(value) {
+	case SortAsc:
+		return SortAsc, nil
+	case SortDesc:
+		return SortDesc, nil
+	}
+	return "", apperrors.InvalidRequestParameterWithValidation(
+		fmt.Sprintf("Sort direction '%s' is not supported", value), param, "direction one of asc, desc", nil)
+}
+
+func joinSortableFields(sortableFields map[string]string) string {
+	names := make([]string, 0, len(sortableFields))
+	for name := range sortableFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	sortableFields := map[string]string{"id": "_id", "name": "name", "created": "created"}
+	tests := []struct {
+		name    string
+		value   string
+		want    []SortOrder
+		wantErr bool
+	}{
+		{name: "single field with default direction", value: "name", want: []SortOrder{{Field: "name", Type: SortAsc}}},
+		{name: "api name is mapped to storage field", value: "id:desc", want: []SortOrder{{Field: "_id", Type: SortDesc}}},
+		{name: "several fields", value: "created:desc, name:asc", want: []SortOrder{{Field: "created", Type: SortDesc}, {Field: "name", Type: SortAsc}}},
+		{name: "unknown field", value: "secret", wantErr: true},
+		{name: "storage name is not accepted", value: "_id", wantErr: true},
+		{name: "duplicate field", value: "name,name:desc", wantErr: true},
+		{name: "unknown direction", value: "name:up", wantErr: true},
+		{name: "direction is case-sensitive", value: "name:DESC", wantErr: true},
+		{name: "empty field", value: "name,", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseSort(test.value, sortableFields, SortAsc)
+			if test.wantErr {
+				if !apperrors.Is(err, apperrors.INVALID_REQUEST_PARAMETERS) {
+					t.Fatalf("ParseSort() error = %v, want INVALID_REQUEST_PARAMETERS", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSort() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseSort() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
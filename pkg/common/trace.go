@@ -0,0 +1,66 @@
+package common
+
+import (
+	"common/logging"
+	"github.com/aws/aws-lambda-go/events"
+	"strings"
+)
+
+const (
+	TraceIdHeader     = "X-Trace-Id"
+	SpanIdHeader      = "X-Span-Id"
+	TraceParentHeader = "traceparent"
+	AmznTraceIdHeader = "X-Amzn-Trace-Id"
+)
+
+// ExtractTraceId returns the trace id of the incoming request. The custom X-Trace-Id header has
+// precedence over W3C traceparent, which has precedence over the AWS X-Ray X-Amzn-Trace-Id header.
+// An empty string is returned when none of them carries a valid trace id, client supplied ids which
+// are not short ids of letters, digits and '-' are ignored.
+func ExtractTraceId(request events.APIGatewayProxyRequest) string {
+	if traceId := strings.TrimSpace(GetHeader(request, TraceIdHeader)); logging.IsValidTraceId(traceId) {
+		return traceId
+	}
+	if traceId := parseTraceParent(GetHeader(request, TraceParentHeader)); traceId != "" {
+		return traceId
+	}
+	if traceId := parseAmznTraceId(GetHeader(request, AmznTraceIdHeader)); logging.IsValidTraceId(traceId) {
+		return traceId
+	}
+	return ""
+}
+
+// parseTraceParent extracts trace id from W3C trace context header, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceParent(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	traceId := strings.ToLower(parts[1])
+	if !isHex(traceId) || strings.Trim(traceId, "0") == "" {
+		return ""
+	}
+	return traceId
+}
+
+// parseAmznTraceId extracts root trace id from AWS X-Ray header, e.g.
+// Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+func parseAmznTraceId(header string) string {
+	for _, part := range strings.Split(header, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if found && key == "Root" {
+			return value
+		}
+	}
+	return ""
+}
+
+func isHex(value string) bool {
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"strings"
+	"testing"
+)
+
+func TestExtractTraceId(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"no headers", nil, ""},
+		{"custom header", map[string]string{"x-trace-id": " abc-123 "}, "abc-123"},
+		{"custom header has precedence", map[string]string{TraceIdHeader: "abc", TraceParentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "abc"},
+		{"custom header with invalid characters", map[string]string{TraceIdHeader: "abc\r\nX-Injected: 1"}, ""},
+		{"custom header too long", map[string]string{TraceIdHeader: strings.Repeat("a", 65)}, ""},
+		{"invalid custom header falls back", map[string]string{TraceIdHeader: "a b", TraceParentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"traceparent", map[string]string{TraceParentHeader: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"traceparent all zero", map[string]string{TraceParentHeader: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, ""},
+		{"traceparent malformed", map[string]string{TraceParentHeader: "00-4bf9-00f067aa0ba902b7-01"}, ""},
+		{"x-ray", map[string]string{AmznTraceIdHeader: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"}, "1-5759e988-bd862e3fe1be46a994272793"},
+		{"x-ray with invalid root", map[string]string{AmznTraceIdHeader: "Root=<script>"}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ExtractTraceId(events.APIGatewayProxyRequest{Headers: test.headers})
+			if got != test.want {
+				t.Errorf("ExtractTraceId() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
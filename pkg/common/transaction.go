@@ -0,0 +1,45 @@
+package common
+
+import (
+	apperrors "common/errors"
+	"context"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransactionRunner runs fn in a transaction. Repositories called with the context passed to fn
+// take part in the transaction, which is committed when fn returns nil and aborted otherwise.
+// fn may be called more than once when the transaction is retried.
+type TransactionRunner interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NoTransaction runs fn directly, it is used with stores which do not support transactions
+type NoTransaction struct{}
+
+func (NoTransaction) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// MongoTransactionRunner runs fn in a Mongo session transaction, which requires a replica set
+type MongoTransactionRunner struct {
+	client *mongo.Client
+}
+
+func NewMongoTransactionRunner(client *mongo.Client) *MongoTransactionRunner {
+	return &MongoTransactionRunner{
+		client: client,
+	}
+}
+
+func (r *MongoTransactionRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := r.client.StartSession()
+	if err != nil {
+		return apperrors.InternalServerError("Failed to start Mongo session", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
@@ -2,27 +2,16 @@ package usecase
 
 import (
 	"context"
-	"log"
-	"time"
 )
 
+// CommandHandler handles a command or a query of type C and returns its result
 type CommandHandler[C any, R any] interface {
 	Handle(ctx context.Context, cmd C) (R, error)
 }
 
-// CommandDecorator is a wrapper around Command that adds additional behavior
-type CommandDecorator[C any, R any] struct {
-	base CommandHandler[C, R]
-}
-
-// Execute logs before and after executing the wrapped command
-func (d CommandDecorator[C, R]) Handle(ctx context.Context, cmd C) (R, error) {
-	start := time.Now()
-	log.Printf("Starting execution of command: %s", cmd)
-
-	result, err := d.base.Handle(ctx, cmd)
-
-	log.Printf("Finished execution in %s with response: %s", time.Since(start), result)
+// CommandHandlerFunc adapts a function to a CommandHandler
+type CommandHandlerFunc[C any, R any] func(ctx context.Context, cmd C) (R, error)
 
-	return result, err
+func (f CommandHandlerFunc[C, R]) Handle(ctx context.Context, cmd C) (R, error) {
+	return f(ctx, cmd)
 }
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"common/validation"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const decoratorComponent = "CommandPipeline"
+
+// Logging logs the start, duration and outcome of every command, commands are redacted and only
+// logged at debug level
+func Logging() Decorator {
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			logger := logging.Log(ctx, decoratorComponent).WithField("command", name)
+			logger.WithField("payload", logging.Redact(cmd)).Debug("Handling command")
+			start := time.Now()
+
+			result, err := next(ctx, cmd)
+
+			logger = logger.WithDuration(time.Since(start))
+			if err != nil {
+				logger.WithError(err).Warn("Command failed")
+			} else {
+				logger.Info("Command handled")
+			}
+			return result, err
+		}
+	}
+}
+
+// MetricsRecorder records the duration and outcome of handled commands
+type MetricsRecorder interface {
+	RecordCommand(ctx context.Context, name string, duration time.Duration, err error)
+}
+
+// Metrics records every command with recorder
+func Metrics(recorder MetricsRecorder) Decorator {
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			start := time.Now()
+			result, err := next(ctx, cmd)
+			recorder.RecordCommand(ctx, name, time.Since(start), err)
+			return result, err
+		}
+	}
+}
+
+// LogMetricsRecorder writes command metrics as log entries, so that they can be extracted with
+// metric filters
+type LogMetricsRecorder struct{}
+
+func (LogMetricsRecorder) RecordCommand(ctx context.Context, name string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		if appError, ok := apperrors.As(err); ok {
+			outcome = appError.ErrorCode
+		}
+	}
+	logging.Log(ctx, decoratorComponent).
+		WithField("metric", "command").
+		WithField("command", name).
+		WithField("outcome", outcome).
+		WithField("durationMs", duration.Milliseconds()).
+		Debug("Command metric")
+}
+
+// Validatable is implemented by commands which check their own fields
+type Validatable interface {
+	Validate() error
+}
+
+// Validation rejects commands violating their validate tags, see validation.Validate, and then
+// commands implementing Validatable whose Validate returns an error
+func Validation() Decorator {
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			if err := validation.Validate(cmd); err != nil {
+				return nil, err
+			}
+			if validatable, ok := cmd.(Validatable); ok {
+				if err := validatable.Validate(); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// Authorizer returns an error when the caller in ctx may not execute the command
+type Authorizer func(ctx context.Context, name string, cmd any) error
+
+// AllowAll authorizes every command, for services whose callers are authorized before the command
+// is handled, e.g. by an API Gateway authorizer
+func AllowAll(ctx context.Context, name string, cmd any) error {
+	return nil
+}
+
+// Authorization rejects commands the authorizer denies, errors which are not an *apperrors.Error
+// are reported as insufficient permission
+func Authorization(authorizer Authorizer) Decorator {
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			if err := authorizer(ctx, name, cmd); err != nil {
+				if _, ok := apperrors.As(err); !ok {
+					return nil, apperrors.UnauthorizedInsufficientPermissions(fmt.Sprintf("%s denied: %v", name, err))
+				}
+				return nil, err
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+type RetryOptions struct {
+	// MaxAttempts includes the first attempt
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Retryable tells whether a failed command may succeed when handled again, nil retries
+	// concurrent modifications like DefaultRetryOptions
+	Retryable func(err error) bool
+}
+
+// DefaultRetryOptions retries commands which lost an optimistic locking race
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     200 * time.Millisecond,
+		Retryable:      isConcurrentModification,
+	}
+}
+
+func isConcurrentModification(err error) bool {
+	return apperrors.Is(err, apperrors.CONCURRENT_MODIFICATION)
+}
+
+// Retry handles a command again with exponential backoff while it fails with a retryable error.
+// Handlers must be safe to repeat, e.g. by reloading the entity they change.
+func Retry(options RetryOptions) Decorator {
+	if options.Retryable == nil {
+		options.Retryable = isConcurrentModification
+	}
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			backoff := options.InitialBackoff
+			for attempt := 1; ; attempt++ {
+				result, err := next(ctx, cmd)
+				if err == nil || attempt >= options.MaxAttempts || !options.Retryable(err) {
+					return result, err
+				}
+				logging.Log(ctx, decoratorComponent).
+					WithField("command", name).
+					WithField("attempt", attempt).
+					WithError(err).
+					Info("Retrying command")
+
+				timer := time.NewTimer(backoff)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return result, err
+				case <-timer.C:
+				}
+				backoff *= 2
+				if backoff > options.MaxBackoff {
+					backoff = options.MaxBackoff
+				}
+			}
+		}
+	}
+}
+
+// Timeout cancels the context of a command after timeout. Handlers have to pass the context on,
+// a command still running when the deadline passes fails with an internal server error.
+func Timeout(timeout time.Duration) Decorator {
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := next(ctx, cmd)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				if _, ok := apperrors.As(err); !ok {
+					return nil, apperrors.InternalServerError(fmt.Sprintf("%s timed out after %s", name, timeout), err)
+				}
+			}
+			return result, err
+		}
+	}
+}
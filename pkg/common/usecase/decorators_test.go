@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	apperrors "common/errors"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type validatedCommand struct {
+	Id string `validate:"required"`
+}
+
+type selfValidatedCommand struct {
+	err error
+}
+
+func (c selfValidatedCommand) Validate() error {
+	return c.err
+}
+
+func handled(ctx context.Context, cmd any) (any, error) {
+	return "handled", nil
+}
+
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      any
+		wantCode string
+	}{
+		{name: "valid tags", cmd: validatedCommand{Id: "order-1"}},
+		{name: "violated tags", cmd: validatedCommand{}, wantCode: apperrors.INVALID_REQUEST_PARAMETERS},
+		{name: "valid command", cmd: selfValidatedCommand{}},
+		{name: "invalid command", cmd: selfValidatedCommand{err: apperrors.InvalidRequestParameter("invalid", "id")}, wantCode: apperrors.INVALID_REQUEST_PARAMETERS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Validation()("command", handled)(context.Background(), tt.cmd)
+			assertOutcome(t, result, err, tt.wantCode)
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		wantCode   string
+	}{
+		{name: "allow all", authorizer: AllowAll},
+		{name: "plain error denies", authorizer: func(ctx context.Context, name string, cmd any) error {
+			return errors.New("not the owner")
+		}, wantCode: apperrors.INSUFFICIENT_PERMISSION},
+		{name: "app error is kept", authorizer: func(ctx context.Context, name string, cmd any) error {
+			return apperrors.EntityNotFound("hidden", "id", "order-1", nil)
+		}, wantCode: apperrors.ENTITY_NOT_FOUND},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Authorization(tt.authorizer)("command", handled)(context.Background(), testCommand{})
+			assertOutcome(t, result, err, tt.wantCode)
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	concurrent := apperrors.ConcurrentModification("order was changed", "id", "order-1", nil)
+	tests := []struct {
+		name         string
+		failures     []error
+		nilRetryable bool
+		wantAttempts int
+		wantCode     string
+	}{
+		{name: "success", wantAttempts: 1},
+		{name: "retried until success", failures: []error{concurrent, concurrent}, wantAttempts: 3},
+		{name: "gives up after max attempts", failures: []error{concurrent, concurrent, concurrent, concurrent}, wantAttempts: 3, wantCode: apperrors.CONCURRENT_MODIFICATION},
+		{name: "default predicate", failures: []error{concurrent}, nilRetryable: true, wantAttempts: 2},
+		{name: "not retryable", failures: []error{apperrors.EntityNotFound("missing", "id", "order-1", nil)}, wantAttempts: 1, wantCode: apperrors.ENTITY_NOT_FOUND},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultRetryOptions()
+			options.InitialBackoff = time.Millisecond
+			options.MaxBackoff = time.Millisecond
+			if tt.nilRetryable {
+				options.Retryable = nil
+			}
+			attempts := 0
+			next := func(ctx context.Context, cmd any) (any, error) {
+				attempts++
+				if attempts <= len(tt.failures) {
+					return nil, tt.failures[attempts-1]
+				}
+				return "handled", nil
+			}
+
+			result, err := Retry(options)("command", next)(context.Background(), testCommand{})
+			assertOutcome(t, result, err, tt.wantCode)
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		next     Next
+		wantCode string
+	}{
+		{name: "in time", next: handled},
+		{name: "deadline exceeded", next: func(ctx context.Context, cmd any) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, wantCode: apperrors.INTERNAL_SERVER_ERROR},
+		{name: "app error is kept", next: func(ctx context.Context, cmd any) (any, error) {
+			<-ctx.Done()
+			return nil, apperrors.EntityNotFound("missing", "id", "order-1", nil)
+		}, wantCode: apperrors.ENTITY_NOT_FOUND},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Timeout(10*time.Millisecond)("command", tt.next)(context.Background(), testCommand{})
+			assertOutcome(t, result, err, tt.wantCode)
+		})
+	}
+}
+
+// assertOutcome checks that the command was handled, or failed with wantCode when it is set
+func assertOutcome(t *testing.T, result any, err error, wantCode string) {
+	t.Helper()
+	if wantCode == "" {
+		if err != nil || result != "handled" {
+			t.Errorf("got (%v, %v), want handled", result, err)
+		}
+		return
+	}
+	if !apperrors.Is(err, wantCode) {
+		t.Errorf("error = %v, want %s", err, wantCode)
+	}
+	if result != nil {
+		t.Errorf("result = %v, want nil", result)
+	}
+}
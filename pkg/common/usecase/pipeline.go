@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+)
+
+// Next handles a command on behalf of a Decorator, it is the next decorator or the handler itself
+type Next func(ctx context.Context, cmd any) (any, error)
+
+// Decorator adds behavior around the handling of a command. Name is the name of the command type,
+// e.g. CreateOrderCommand. Decorators see commands untyped, so the same decorator serves every handler.
+type Decorator func(name string, next Next) Next
+
+// Pipeline is an ordered list of decorators, the first one is the outermost
+type Pipeline struct {
+	decorators []Decorator
+}
+
+func NewPipeline(decorators ...Decorator) *Pipeline {
+	return &Pipeline{
+		decorators: decorators,
+	}
+}
+
+// With returns a new pipeline running the given decorators after the ones of p
+func (p *Pipeline) With(decorators ...Decorator) *Pipeline {
+	combined := make([]Decorator, 0, len(p.decorators)+len(decorators))
+	combined = append(combined, p.decorators...)
+	return &Pipeline{
+		decorators: append(combined, decorators...),
+	}
+}
+
+// Decorate wraps handler with the decorators of the pipeline
+func Decorate[C any, R any](pipeline *Pipeline, handler CommandHandler[C, R]) CommandHandler[C, R] {
+	name := commandName[C]()
+	next := Next(func(ctx context.Context, cmd any) (any, error) {
+		return handler.Handle(ctx, cmd.(C))
+	})
+	for i := len(pipeline.decorators) - 1; i >= 0; i-- {
+		next = pipeline.decorators[i](name, next)
+	}
+	return &decoratedHandler[C, R]{
+		next: next,
+	}
+}
+
+type decoratedHandler[C any, R any] struct {
+	next Next
+}
+
+func (h *decoratedHandler[C, R]) Handle(ctx context.Context, cmd C) (R, error) {
+	result, err := h.next(ctx, cmd)
+	// a nil result, e.g. on error, is the zero value of R
+	typed, _ := result.(R)
+	return typed, err
+}
+
+func commandName[C any]() string {
+	commandType := reflect.TypeOf((*C)(nil)).Elem()
+	for commandType.Kind() == reflect.Pointer {
+		commandType = commandType.Elem()
+	}
+	if commandType.Name() == "" {
+		return commandType.String()
+	}
+	return commandType.Name()
+}
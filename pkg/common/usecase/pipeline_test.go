@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testCommand struct {
+	Value string
+}
+
+func recordingDecorator(label string, calls *[]string) Decorator {
+	return func(name string, next Next) Next {
+		return func(ctx context.Context, cmd any) (any, error) {
+			*calls = append(*calls, label+":"+name)
+			return next(ctx, cmd)
+		}
+	}
+}
+
+func TestDecorate(t *testing.T) {
+	tests := []struct {
+		name       string
+		decorators []string
+		with       []string
+		handlerErr error
+		want       *string
+		wantCalls  []string
+	}{
+		{name: "no decorators", wantCalls: []string{"handler"}},
+		{name: "first decorator is outermost", decorators: []string{"a", "b"}, wantCalls: []string{"a:testCommand", "b:testCommand", "handler"}},
+		{name: "with runs after pipeline", decorators: []string{"a"}, with: []string{"b"}, wantCalls: []string{"a:testCommand", "b:testCommand", "handler"}},
+		{name: "error result is zero value", decorators: []string{"a"}, handlerErr: errors.New("failed"), wantCalls: []string{"a:testCommand", "handler"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var decorators, with []Decorator
+			for _, label := range tt.decorators {
+				decorators = append(decorators, recordingDecorator(label, &calls))
+			}
+			for _, label := range tt.with {
+				with = append(with, recordingDecorator(label, &calls))
+			}
+			handler := CommandHandlerFunc[testCommand, *string](func(ctx context.Context, cmd testCommand) (*string, error) {
+				calls = append(calls, "handler")
+				if tt.handlerErr != nil {
+					return nil, tt.handlerErr
+				}
+				return &cmd.Value, nil
+			})
+
+			result, err := Decorate[testCommand, *string](NewPipeline(decorators...).With(with...), handler).Handle(context.Background(), testCommand{Value: "value"})
+			if !errors.Is(err, tt.handlerErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.handlerErr)
+			}
+			if tt.handlerErr == nil && (result == nil || *result != "value") {
+				t.Errorf("Handle() = %v, want value", result)
+			}
+			if tt.handlerErr != nil && result != nil {
+				t.Errorf("Handle() = %v, want nil", result)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"struct", commandName[testCommand](), "testCommand"},
+		{"pointer", commandName[*testCommand](), "testCommand"},
+		{"unnamed", commandName[[]string](), "[]string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("commandName() = %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package validation
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Validate checks the fields of the struct value points to against their validate tags:
+//
+//	Id     string `json:"id" validate:"required,maxLength=64,pattern=^[A-Za-z0-9_-]+$"`
+//	Status string `json:"status" validate:"enum=PENDING|PAID"`
+//	Count  int    `json:"count" validate:"min=1,max=100"`
+//
+// Supported rules are required, minLength and maxLength (characters of a string, elements of a
+// slice or map), min and max (numbers), enum (alternatives separated by |) and pattern, which has
+// to be the last rule as the expression may contain commas. Nested structs, pointers to structs and
+// slices of structs are validated as well, `validate:"-"` skips a field. Fields are named by their
+// json name, e.g. items[0].quantity. Every violation is reported at once in a single
+// INVALID_REQUEST_PARAMETERS error listing an apperrors.FieldViolation per invalid field.
+func Validate(value interface{}) error {
+	violations := &violations{}
+	validateValue(reflect.ValueOf(value), "", violations)
+	if violations.err != nil {
+		return violations.err
+	}
+	if len(violations.fields) == 0 {
+		return nil
+	}
+	return apperrors.InvalidRequestParameters(violations.message(), violations.fields)
+}
+
+// maxRejectedValueLength limits the length of a rejected string echoed back to the client
+const maxRejectedValueLength = 64
+
+type violations struct {
+	fields []apperrors.FieldViolation
+	// err is set when a tag can not be parsed, which is a programming error
+	err error
+}
+
+func (v *violations) add(field string, rule string, message string, value reflect.Value) {
+	v.fields = append(v.fields, apperrors.FieldViolation{
+		Field:         field,
+		Rule:          rule,
+		Message:       message,
+		RejectedValue: rejectedValue(field, value),
+	})
+}
+
+func (v *violations) message() string {
+	messages := make([]string, 0, len(v.fields))
+	for _, field := range v.fields {
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+	return fmt.Sprintf("Request has %d invalid parameters: %s", len(v.fields), strings.Join(messages, "; "))
+}
+
+// rejectedValue returns scalar values only, long strings are truncated and sensitive fields redacted
+func rejectedValue(field string, value reflect.Value) interface{} {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() || (value.Kind() == reflect.String && value.Len() == 0) {
+		return nil
+	}
+	if name := field[strings.LastIndex(field, ".")+1:]; logging.IsSensitiveField(name) {
+		return logging.RedactedValue
+	}
+	switch value.Kind() {
+	case reflect.String:
+		rejected := value.String()
+		if utf8.RuneCountInString(rejected) > maxRejectedValueLength {
+			rejected = string([]rune(rejected)[:maxRejectedValueLength]) + "..."
+		}
+		return rejected
+	case reflect.Bool, reflect.Float32, reflect.Float64:
+		return value.Interface()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint()
+	}
+	return nil
+}
+
+func validateValue(value reflect.Value, path string, violations *violations) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		validateStruct(value, path, violations)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+func validateStruct(value reflect.Value, path string, violations *violations) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, fieldName(field))
+		fieldValue := value.Field(i)
+		if field.Anonymous {
+			// embedded fields are promoted, so they are named like fields of the outer struct
+			fieldPath = path
+		}
+		if tag != "" && !validateField(fieldValue, fieldPath, tag, violations) {
+			continue
+		}
+		validateValue(fieldValue, fieldPath, violations)
+	}
+}
+
+// validateField applies the rules of tag and reports whether the field is valid. Rules after the
+// first violated one are not checked.
+func validateField(value reflect.Value, path string, tag string, violations *violations) bool {
+	rules, err := parseRules(tag)
+	if err != nil {
+		violations.err = apperrors.InternalServerError(fmt.Sprintf("Invalid validate tag of %s", path), err)
+		return false
+	}
+	for _, rule := range rules {
+		message, err := rule.check(value)
+		if err != nil {
+			violations.err = apperrors.InternalServerError(fmt.Sprintf("Rule %s can not validate %s", rule, path), err)
+			return false
+		}
+		if message != "" {
+			violations.add(path, rule.String(), message, value)
+			return false
+		}
+	}
+	return true
+}
+
+type rule struct {
+	name     string
+	argument string
+}
+
+func (r rule) String() string {
+	if r.argument == "" {
+		return r.name
+	}
+	return r.name + "=" + r.argument
+}
+
+func parseRules(tag string) ([]rule, error) {
+	var rules []rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "pattern=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, argument, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required":
+		case "minLength", "maxLength":
+			if _, err := strconv.Atoi(argument); err != nil {
+				return nil, fmt.Errorf("%s needs an integer argument, got '%s'", name, argument)
+			}
+		case "min", "max":
+			if _, err := strconv.ParseFloat(argument, 64); err != nil {
+				return nil, fmt.Errorf("%s needs a number argument, got '%s'", name, argument)
+			}
+		case "enum":
+			if argument == "" {
+				return nil, fmt.Errorf("enum needs alternatives")
+			}
+		case "pattern":
+			if _, err := compilePattern(argument); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule '%s'", name)
+		}
+		rules = append(rules, rule{name: name, argument: argument})
+	}
+	return rules, nil
+}
+
+// check returns the violation message, which is empty when the value satisfies the rule
+func (r rule) check(value reflect.Value) (string, error) {
+	if r.name == "required" {
+		if value.IsZero() || (isSized(value) && value.Len() == 0) {
+			return "is required", nil
+		}
+		return "", nil
+	}
+
+	for value.Kind() == reflect.Pointer {
+		// only required applies to a missing optional value
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	switch r.name {
+	case "minLength", "maxLength":
+		if !isSized(value) {
+			return "", fmt.Errorf("%s has no length", value.Type())
+		}
+		limit, _ := strconv.Atoi(r.argument)
+		length := value.Len()
+		if value.Kind() == reflect.String {
+			length = utf8.RuneCountInString(value.String())
+		}
+		if r.name == "minLength" && length < limit {
+			return fmt.Sprintf("must have at least %d %s", limit, lengthUnit(value)), nil
+		}
+		if r.name == "maxLength" && length > limit {
+			return fmt.Sprintf("must have at most %d %s", limit, lengthUnit(value)), nil
+		}
+	case "min", "max":
+		number, ok := toFloat(value)
+		if !ok {
+			return "", fmt.Errorf("%s is not a number", value.Type())
+		}
+		limit, _ := strconv.ParseFloat(r.argument, 64)
+		if r.name == "min" && number < limit {
+			return fmt.Sprintf("must be at least %s", r.argument), nil
+		}
+		if r.name == "max" && number > limit {
+			return fmt.Sprintf("must be at most %s", r.argument), nil
+		}
+	case "enum":
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("%s is not a string", value.Type())
+		}
+		// an empty value is left to required
+		if value.String() == "" {
+			return "", nil
+		}
+		for _, alternative := range strings.Split(r.argument, "|") {
+			if value.String() == alternative {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(r.argument, "|", ", ")), nil
+	case "pattern":
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("%s is not a string", value.Type())
+		}
+		if value.String() == "" {
+			return "", nil
+		}
+		pattern, _ := compilePattern(r.argument)
+		if !pattern.MatchString(value.String()) {
+			return fmt.Sprintf("must match %s", r.argument), nil
+		}
+	}
+	return "", nil
+}
+
+func isSized(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func lengthUnit(value reflect.Value) string {
+	if value.Kind() == reflect.String {
+		return "characters"
+	}
+	return "elements"
+}
+
+func toFloat(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+var patterns sync.Map
+
+func compilePattern(expression string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(expression); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", expression, err)
+	}
+	patterns.Store(expression, pattern)
+	return pattern, nil
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
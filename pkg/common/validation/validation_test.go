@@ -0,0 +1,143 @@
+package validation
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"reflect"
+	"testing"
+)
+
+type item struct {
+	Sku      string `json:"sku" validate:"required,maxLength=8"`
+	Quantity int64  `json:"quantity" validate:"min=1,max=10"`
+}
+
+type order struct {
+	Id       string   `json:"id" validate:"required,pattern=^[a-z0-9,-]+$"`
+	Status   string   `json:"status" validate:"enum=PENDING|PAID"`
+	Version  *int     `json:"version,omitempty" validate:"min=1"`
+	Items    []item   `json:"items" validate:"minLength=1,maxLength=2"`
+	Password string   `json:"password" validate:"minLength=8"`
+	Note     string   `validate:"-"`
+	Tags     []string `json:"tags"`
+	Billing  *Address `json:"billing"`
+	Address
+}
+
+type Address struct {
+	City string `json:"city" validate:"maxLength=4"`
+}
+
+func validOrder() order {
+	return order{Id: "order-1", Status: "PAID", Items: []item{{Sku: "sku", Quantity: 1}}, Password: "long enough"}
+}
+
+func TestValidate(t *testing.T) {
+	zero, tooLong := 0, 2
+
+	tests := []struct {
+		name   string
+		modify func(o *order)
+		want   []apperrors.FieldViolation
+	}{
+		{name: "valid", modify: func(o *order) {}},
+		{name: "pattern with comma", modify: func(o *order) { o.Id = "a,b" }},
+		{name: "required", modify: func(o *order) { o.Id = "" }, want: []apperrors.FieldViolation{
+			{Field: "id", Rule: "required", Message: "is required"},
+		}},
+		{name: "pattern", modify: func(o *order) { o.Id = "Order 1" }, want: []apperrors.FieldViolation{
+			{Field: "id", Rule: "pattern=^[a-z0-9,-]+$", Message: "must match ^[a-z0-9,-]+$", RejectedValue: "Order 1"},
+		}},
+		{name: "enum", modify: func(o *order) { o.Status = "SHIPPED" }, want: []apperrors.FieldViolation{
+			{Field: "status", Rule: "enum=PENDING|PAID", Message: "must be one of PENDING, PAID", RejectedValue: "SHIPPED"},
+		}},
+		{name: "empty enum is left to required", modify: func(o *order) { o.Status = "" }},
+		{name: "nil optional value", modify: func(o *order) { o.Version = nil }},
+		{name: "optional value", modify: func(o *order) { o.Version = &tooLong }},
+		{name: "min of optional value", modify: func(o *order) { o.Version = &zero }, want: []apperrors.FieldViolation{
+			{Field: "version", Rule: "min=1", Message: "must be at least 1", RejectedValue: int64(0)},
+		}},
+		{name: "min length of slice", modify: func(o *order) { o.Items = nil }, want: []apperrors.FieldViolation{
+			{Field: "items", Rule: "minLength=1", Message: "must have at least 1 elements"},
+		}},
+		{name: "max length of slice", modify: func(o *order) { o.Items = []item{{"a", 1}, {"b", 1}, {"c", 1}} }, want: []apperrors.FieldViolation{
+			{Field: "items", Rule: "maxLength=2", Message: "must have at most 2 elements"},
+		}},
+		{name: "nested slice elements", modify: func(o *order) { o.Items = []item{{"sku", 1}, {"", 11}} }, want: []apperrors.FieldViolation{
+			{Field: "items[1].sku", Rule: "required", Message: "is required"},
+			{Field: "items[1].quantity", Rule: "max=10", Message: "must be at most 10", RejectedValue: int64(11)},
+		}},
+		{name: "max length counts characters", modify: func(o *order) { o.Items[0].Sku = "äöüäöüäö" }},
+		{name: "sensitive value is redacted", modify: func(o *order) { o.Password = "short" }, want: []apperrors.FieldViolation{
+			{Field: "password", Rule: "minLength=8", Message: "must have at least 8 characters", RejectedValue: logging.RedactedValue},
+		}},
+		{name: "skipped field", modify: func(o *order) { o.Note = "anything" }},
+		{name: "pointer to struct", modify: func(o *order) { o.Billing = &Address{City: "Berlin"} }, want: []apperrors.FieldViolation{
+			{Field: "billing.city", Rule: "maxLength=4", Message: "must have at most 4 characters", RejectedValue: "Berlin"},
+		}},
+		{name: "embedded struct", modify: func(o *order) { o.City = "Berlin" }, want: []apperrors.FieldViolation{
+			{Field: "city", Rule: "maxLength=4", Message: "must have at most 4 characters", RejectedValue: "Berlin"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := validOrder()
+			tt.modify(&value)
+
+			err := Validate(&value)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			appError, ok := apperrors.As(err)
+			if !ok || appError.ErrorCode != apperrors.INVALID_REQUEST_PARAMETERS {
+				t.Fatalf("Validate() error = %v, want %s", err, apperrors.INVALID_REQUEST_PARAMETERS)
+			}
+			if !reflect.DeepEqual(appError.Violations, tt.want) {
+				t.Errorf("Violations = %+v, want %+v", appError.Violations, tt.want)
+			}
+			wantParams := map[string]string{"param": tt.want[0].Field, "rule": tt.want[0].Rule}
+			if !reflect.DeepEqual(appError.Params, wantParams) {
+				t.Errorf("Params = %v, want %v", appError.Params, wantParams)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"unknown rule", &struct {
+			Name string `validate:"unique"`
+		}{}},
+		{"length argument", &struct {
+			Name string `validate:"maxLength=many"`
+		}{}},
+		{"number argument", &struct {
+			Count int `validate:"min=one"`
+		}{}},
+		{"empty enum", &struct {
+			Status string `validate:"enum="`
+		}{}},
+		{"invalid pattern", &struct {
+			Name string `validate:"pattern=[a-"`
+		}{}},
+		{"length of a number", &struct {
+			Count int `validate:"maxLength=1"`
+		}{Count: 1}},
+		{"range of a string", &struct {
+			Name string `validate:"min=1"`
+		}{Name: "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.value); !apperrors.Is(err, apperrors.INTERNAL_SERVER_ERROR) {
+				t.Errorf("Validate() error = %v, want %s", err, apperrors.INTERNAL_SERVER_ERROR)
+			}
+		})
+	}
+}
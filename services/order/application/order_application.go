@@ -1,21 +1,35 @@
 package application
 
-import "order/application/usecase"
+import (
+	"common"
+	commonusecase "common/usecase"
+	"order/application/usecase"
+	"order/domain"
+)
 
+// OrderApplication holds the handlers of the order service decorated with the command pipeline
 type OrderApplication struct {
-	GetOrderQueryHandler      *usecase.GetOrderQueryHandler
-	GetAllOrdersQueryHandler  *usecase.GetAllOrdersQueryHandler
-	CreateOrderCommandHandler *usecase.CreateOrderCommandHandler
+	GetOrderQueryHandler             commonusecase.CommandHandler[usecase.GetOrderQuery, *domain.Order]
+	GetAllOrdersQueryHandler         commonusecase.CommandHandler[usecase.GetAllOrdersQuery, *common.Paginated[domain.Order]]
+	GetAllOrdersByCursorQueryHandler commonusecase.CommandHandler[usecase.GetAllOrdersByCursorQuery, *common.CursorPaginated[domain.Order]]
+	CreateOrderCommandHandler        commonusecase.CommandHandler[usecase.CreateOrderCommand, *domain.Order]
+	ChangeOrderStatusCommandHandler  commonusecase.CommandHandler[usecase.ChangeOrderStatusCommand, *domain.Order]
 }
 
+// NewOrderApplication decorates every handler with pipeline
 func NewOrderApplication(
+	pipeline *commonusecase.Pipeline,
 	getOrderQueryHandler *usecase.GetOrderQueryHandler,
 	getAllOrdersQueryHandler *usecase.GetAllOrdersQueryHandler,
+	getAllOrdersByCursorQueryHandler *usecase.GetAllOrdersByCursorQueryHandler,
 	createOrderCommandHandler *usecase.CreateOrderCommandHandler,
+	changeOrderStatusCommandHandler *usecase.ChangeOrderStatusCommandHandler,
 ) *OrderApplication {
 	return &OrderApplication{
-		GetOrderQueryHandler:      getOrderQueryHandler,
-		GetAllOrdersQueryHandler:  getAllOrdersQueryHandler,
-		CreateOrderCommandHandler: createOrderCommandHandler,
+		GetOrderQueryHandler:             commonusecase.Decorate[usecase.GetOrderQuery, *domain.Order](pipeline, getOrderQueryHandler),
+		GetAllOrdersQueryHandler:         commonusecase.Decorate[usecase.GetAllOrdersQuery, *common.Paginated[domain.Order]](pipeline, getAllOrdersQueryHandler),
+		GetAllOrdersByCursorQueryHandler: commonusecase.Decorate[usecase.GetAllOrdersByCursorQuery, *common.CursorPaginated[domain.Order]](pipeline, getAllOrdersByCursorQueryHandler),
+		CreateOrderCommandHandler:        commonusecase.Decorate[usecase.CreateOrderCommand, *domain.Order](pipeline, createOrderCommandHandler),
+		ChangeOrderStatusCommandHandler:  commonusecase.Decorate[usecase.ChangeOrderStatusCommand, *domain.Order](pipeline, changeOrderStatusCommandHandler),
 	}
 }
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"common"
+	apperrors "common/errors"
+	"common/messaging"
+	"context"
+	"fmt"
+	"order/domain"
+)
+
+type ChangeOrderStatusCommand struct {
+	Id              string             `json:"id" validate:"required,maxLength=64"`
+	Action          domain.OrderAction `json:"action" validate:"required,enum=confirm|pay|ship|deliver|cancel|refund"`
+	ExpectedVersion *int               `json:"expectedVersion,omitempty" validate:"min=1"`
+}
+
+type ChangeOrderStatusCommandHandler struct {
+	orderRepository domain.OrderRepository
+	publisher       messaging.Publisher
+	transactions    common.TransactionRunner
+}
+
+func NewChangeOrderStatusCommandHandler(orderRepository domain.OrderRepository, publisher messaging.Publisher, transactions common.TransactionRunner) *ChangeOrderStatusCommandHandler {
+	return &ChangeOrderStatusCommandHandler{
+		orderRepository: orderRepository,
+		publisher:       publisher,
+		transactions:    transactions,
+	}
+}
+
+// Handle loads the order, applies the action and saves it. When ExpectedVersion is given the order
+// must still have that version, as requested by the If-Match header.
+func (h *ChangeOrderStatusCommandHandler) Handle(ctx context.Context, cmd ChangeOrderStatusCommand) (*domain.Order, error) {
+	order, err := h.orderRepository.GetById(ctx, cmd.Id)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.ExpectedVersion != nil && *cmd.ExpectedVersion != order.Version {
+		return nil, apperrors.PreconditionFailed(
+			fmt.Sprintf("Order %s has version %d, expected %d", cmd.Id, order.Version, *cmd.ExpectedVersion), common.IfMatchHeader)
+	}
+	if err := order.Apply(cmd.Action); err != nil {
+		return nil, err
+	}
+	return order, saveAndPublish(ctx, h.orderRepository, h.publisher, h.transactions, order)
+}
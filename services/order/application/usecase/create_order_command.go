@@ -1,33 +1,57 @@
 package usecase
 
 import (
+	"common"
+	"common/messaging"
 	"context"
 	"order/domain"
 )
 
 type CreateOrderCommand struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id    string                   `json:"id" validate:"required,maxLength=64,pattern=^[A-Za-z0-9_-]+$"`
+	Name  string                   `json:"name" validate:"required,maxLength=256"`
+	Items []CreateOrderItemCommand `json:"items" validate:"required,maxLength=100"`
+	// TaxRate in basis points, 2100 is 21%
+	TaxRate  domain.BasisPoints `json:"taxRate" validate:"min=0,max=10000"`
+	Discount domain.Money       `json:"discount"`
+}
+
+type CreateOrderItemCommand struct {
+	Sku         string       `json:"sku" validate:"required,maxLength=64"`
+	Description string       `json:"description" validate:"maxLength=1024"`
+	Quantity    int64        `json:"quantity" validate:"min=1"`
+	UnitPrice   domain.Money `json:"unitPrice"`
 }
 
 type CreateOrderCommandHandler struct {
 	orderRepository domain.OrderRepository
+	publisher       messaging.Publisher
+	transactions    common.TransactionRunner
 }
 
-func NewCreateOrderCommandHandler(orderRepository domain.OrderRepository) *CreateOrderCommandHandler {
+func NewCreateOrderCommandHandler(orderRepository domain.OrderRepository, publisher messaging.Publisher, transactions common.TransactionRunner) *CreateOrderCommandHandler {
 	return &CreateOrderCommandHandler{
 		orderRepository: orderRepository,
+		publisher:       publisher,
+		transactions:    transactions,
 	}
 }
 
-func (h *CreateOrderCommandHandler) Execute(ctx context.Context, cmd CreateOrderCommand) (*domain.Order, error) {
+func (h *CreateOrderCommandHandler) Handle(ctx context.Context, cmd CreateOrderCommand) (*domain.Order, error) {
+	items := make([]domain.LineItem, 0, len(cmd.Items))
+	for _, item := range cmd.Items {
+		items = append(items, domain.NewLineItem(item.Sku, item.Description, item.Quantity, item.UnitPrice))
+	}
 	order, err := domain.CreateOrder(
 		ctx,
 		cmd.Id,
 		cmd.Name,
+		items,
+		cmd.TaxRate,
+		cmd.Discount,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return order, h.orderRepository.Save(ctx, order)
+	return order, saveAndPublish(ctx, h.orderRepository, h.publisher, h.transactions, order)
 }
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"common"
+	"context"
+	"order/domain"
+)
+
+type GetAllOrdersByCursorQuery struct {
+	Filter *domain.OrderFilter      `validate:"required"`
+	Page   *common.CursorPageFilter `validate:"required"`
+}
+
+type GetAllOrdersByCursorQueryHandler struct {
+	orderRepository domain.OrderRepository
+}
+
+func NewGetAllOrdersByCursorQueryHandler(orderRepository domain.OrderRepository) *GetAllOrdersByCursorQueryHandler {
+	return &GetAllOrdersByCursorQueryHandler{
+		orderRepository: orderRepository,
+	}
+}
+
+func (h *GetAllOrdersByCursorQueryHandler) Handle(ctx context.Context, q GetAllOrdersByCursorQuery) (*common.CursorPaginated[domain.Order], error) {
+	return h.orderRepository.GetAllByCursor(ctx, q.Filter, q.Page)
+}
@@ -7,8 +7,8 @@ import (
 )
 
 type GetAllOrdersQuery struct {
-	Filter *domain.OrderFilter
-	Page   *common.PageFilter
+	Filter *domain.OrderFilter `validate:"required"`
+	Page   *common.PageFilter  `validate:"required"`
 }
 
 type GetAllOrdersQueryHandler struct {
@@ -21,6 +21,6 @@ func NewGetAllOrdersQueryHandler(orderRepository domain.OrderRepository) *GetAll
 	}
 }
 
-func (h *GetAllOrdersQueryHandler) Execute(ctx context.Context, q GetAllOrdersQuery) (*common.Paginated[domain.Order], error) {
+func (h *GetAllOrdersQueryHandler) Handle(ctx context.Context, q GetAllOrdersQuery) (*common.Paginated[domain.Order], error) {
 	return h.orderRepository.GetAll(ctx, q.Filter, q.Page)
 }
@@ -1,13 +1,12 @@
 package usecase
 
 import (
-	apperrors "common/errors"
 	"context"
 	"order/domain"
 )
 
 type GetOrderQuery struct {
-	Id string `json:"id"`
+	Id string `json:"id" validate:"required,maxLength=64"`
 }
 
 type GetOrderQueryHandler struct {
@@ -20,9 +19,6 @@ func NewGetOrderQueryHandler(orderRepository domain.OrderRepository) *GetOrderQu
 	}
 }
 
-func (h *GetOrderQueryHandler) Execute(ctx context.Context, query GetOrderQuery) (*domain.Order, error) {
-	if len(query.Id) < 1 {
-		return nil, apperrors.InvalidRequestParameter("id can not be empty", "id")
-	}
+func (h *GetOrderQueryHandler) Handle(ctx context.Context, query GetOrderQuery) (*domain.Order, error) {
 	return h.orderRepository.GetById(ctx, query.Id)
 }
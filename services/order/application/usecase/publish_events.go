@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"common"
+	"common/logging"
+	"common/messaging"
+	"context"
+	"order/domain"
+)
+
+// saveAndPublish saves the order and publishes the events it has recorded in one transaction, so
+// with an outbox publisher the events are stored if and only if the order is. Without transactions
+// the order stays saved when publishing fails, the failure is logged and the command succeeds, as a
+// retry of the client would be rejected by the saved order.
+func saveAndPublish(ctx context.Context, orderRepository domain.OrderRepository, publisher messaging.Publisher, transactions common.TransactionRunner, order *domain.Order) error {
+	events := order.PullEvents()
+	version := order.Version
+	if _, ok := transactions.(common.NoTransaction); ok {
+		if err := orderRepository.Save(ctx, order); err != nil {
+			order.Version = version
+			return err
+		}
+		if err := publisher.Publish(ctx, events...); err != nil {
+			logging.Log(ctx, "OrderEvents").
+				WithField("orderId", order.Id).
+				WithField("events", len(events)).
+				WithError(err).
+				Error("Order was saved but its events were not published")
+		}
+		return nil
+	}
+
+	err := transactions.WithTransaction(ctx, func(ctx context.Context) error {
+		// the transaction may be retried, every attempt saves the order as it was loaded
+		order.Version = version
+		if err := orderRepository.Save(ctx, order); err != nil {
+			return err
+		}
+		return publisher.Publish(ctx, events...)
+	})
+	if err != nil {
+		order.Version = version
+	}
+	return err
+}
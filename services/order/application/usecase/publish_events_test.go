@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"common"
+	"common/messaging"
+	"context"
+	"errors"
+	"order/domain"
+	"order/infrastructure"
+	"testing"
+)
+
+// transactionRunner runs fn directly but is not NoTransaction, like a Mongo transaction whose
+// rollback is not observable in the in-memory repository
+type transactionRunner struct{}
+
+func (transactionRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestSaveAndPublish(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions common.TransactionRunner
+		publishErr   error
+		exists       bool
+		wantErr      bool
+		wantSaved    bool
+		wantVersion  int
+		wantEvents   int
+	}{
+		{name: "without transaction", transactions: common.NoTransaction{}, wantSaved: true, wantVersion: 1, wantEvents: 1},
+		{name: "publish failure without transaction is logged", transactions: common.NoTransaction{}, publishErr: errors.New("unavailable"), wantSaved: true, wantVersion: 1},
+		{name: "save failure without transaction", transactions: common.NoTransaction{}, exists: true, wantErr: true},
+		{name: "in transaction", transactions: transactionRunner{}, wantSaved: true, wantVersion: 1, wantEvents: 1},
+		{name: "publish failure fails the transaction", transactions: transactionRunner{}, publishErr: errors.New("unavailable"), wantErr: true, wantSaved: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := infrastructure.NewInMemoryOrderRepository()
+			publisher := messaging.NewFakePublisher("order")
+			publisher.FailWith(test.publishErr)
+
+			newOrder := func() *domain.Order {
+				order, err := domain.CreateOrder(ctx, "order-1", "Order", []domain.LineItem{domain.NewLineItem("sku-1", "Item", 1, domain.NewMoney(100, "EUR"))}, 0, domain.NewMoney(0, "EUR"))
+				if err != nil {
+					t.Fatalf("CreateOrder() error = %v", err)
+				}
+				return order
+			}
+			if test.exists {
+				if err := repository.Save(ctx, newOrder()); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			order := newOrder()
+			err := saveAndPublish(ctx, repository, publisher, test.transactions, order)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("saveAndPublish() error = %v, want error %v", err, test.wantErr)
+			}
+			if order.Version != test.wantVersion {
+				t.Errorf("Version = %d, want %d", order.Version, test.wantVersion)
+			}
+			if _, err := repository.GetById(ctx, order.Id); (err == nil) != (test.wantSaved || test.exists) {
+				t.Errorf("GetById() error = %v, want saved %v", err, test.wantSaved)
+			}
+			if events := len(publisher.Messages()); events != test.wantEvents {
+				t.Errorf("published %d events, want %d", events, test.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import "time"
+
+const (
+	// RunModeApi serves the HTTP API
+	RunModeApi = "api"
+	// RunModeOutboxRelay publishes pending outbox entries once per invocation, e.g. on a schedule
+	RunModeOutboxRelay = "outbox-relay"
+	// RunModeOutboxRelayLoop publishes pending outbox entries until the process is stopped, for local runs
+	RunModeOutboxRelayLoop = "outbox-relay-loop"
+	// RunModePaymentConsumer handles payment events delivered from SQS
+	RunModePaymentConsumer = "payment-consumer"
+	// RunModeMigrate prepares the Mongo collections of a new release and exits, it is run once per
+	// deployment before the API of the release serves requests
+	RunModeMigrate = "migrate"
+)
+
+// Config of the order service, loaded from environment variables at cold start
+type Config struct {
+	RunMode string `env:"RUN_MODE" default:"api"`
+
+	// OrderRepository is either mongo or memory, the latter is meant for local runs
+	OrderRepository string `env:"ORDER_REPOSITORY" default:"mongo"`
+
+	MongoUrl               string        `env:"MONGO_URL"`
+	MongoDatabaseName      string        `env:"MONGO_DB_NAME"`
+	MongoConnectionTimeout time.Duration `env:"MONGO_CONNECTION_TIMEOUT" default:"10s"`
+
+	// MongoSecretArn names the secret holding MongoCredentials as JSON. When it is not set
+	// credentials are read from DB_USERNAME and DB_PASSWORD.
+	MongoSecretArn string `env:"MONGO_SECRET_ARN"`
+	MongoUsername  string `env:"DB_USERNAME"`
+	MongoPassword  string `env:"DB_PASSWORD" log:"redact"`
+
+	// EventPublisher is one of eventbridge, sns, sqs, log or memory, the last two are meant for
+	// local runs. EventSource is the CloudEvents source of published events.
+	EventPublisher string `env:"EVENT_PUBLISHER" default:"log"`
+	EventSource    string `env:"EVENT_SOURCE" default:"order-service"`
+	EventBusName   string `env:"EVENT_BUS_NAME" default:"default"`
+	EventTopicArn  string `env:"EVENT_TOPIC_ARN"`
+	EventQueueUrl  string `env:"EVENT_QUEUE_URL"`
+
+	// OutboxEnabled stores events in an outbox in the same Mongo transaction as the order, they
+	// are published by the outbox relay. Transactions require a replica set and the outbox collection
+	// created by RUN_MODE=migrate.
+	OutboxEnabled        bool          `env:"OUTBOX_ENABLED" default:"true"`
+	OutboxRelayInterval  time.Duration `env:"OUTBOX_RELAY_INTERVAL" default:"5s"`
+	OutboxBatchSize      int           `env:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxMaxAttempts    int           `env:"OUTBOX_MAX_ATTEMPTS" default:"10"`
+	OutboxInitialBackoff time.Duration `env:"OUTBOX_INITIAL_BACKOFF" default:"1s"`
+	OutboxMaxBackoff     time.Duration `env:"OUTBOX_MAX_BACKOFF" default:"10m"`
+	OutboxLease          time.Duration `env:"OUTBOX_LEASE" default:"1m"`
+
+	// ConsumerConcurrency is the number of SQS messages of a batch handled at the same time.
+	// ConsumerMaxReceiveCount drops a message after that many deliveries, by default 0 leaves it to the
+	// redrive policy of the queue, which moves the message to the dead-letter queue instead of losing it.
+	ConsumerConcurrency     int `env:"CONSUMER_CONCURRENCY" default:"10"`
+	ConsumerMaxReceiveCount int `env:"CONSUMER_MAX_RECEIVE_COUNT" default:"0"`
+
+	// ErrorFormat of error responses is legacy or problem (RFC 7807), clients accepting
+	// application/problem+json always get problem details
+	ErrorFormat string `env:"ERROR_FORMAT" default:"legacy"`
+
+	// CommandTimeout limits the handling of a single command or query including its retries
+	CommandTimeout time.Duration `env:"COMMAND_TIMEOUT" default:"10s"`
+
+	// CursorSigningKey signs continuation tokens of GET /orders, it must be the same for all
+	// function instances. It is required by the API unless ORDER_REPOSITORY is memory, for which a
+	// random key is generated.
+	CursorSigningKey string `env:"CURSOR_SIGNING_KEY" log:"redact"`
+
+	// SecretsProvider is one of secretsmanager, ssm, env or file. Secrets are cached for SecretsCacheTtl
+	// and read again when authentication with them fails.
+	SecretsProvider  string        `env:"SECRETS_PROVIDER" default:"secretsmanager"`
+	SecretsDirectory string        `env:"SECRETS_DIR" default:"./secrets"`
+	SecretsCacheTtl  time.Duration `env:"SECRETS_CACHE_TTL" default:"5m"`
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	apperrors "common/errors"
+	"common/logging"
+	"common/messaging"
+	"context"
+	"fmt"
+	"order/application/usecase"
+	"order/domain"
+	"slices"
+)
+
+const (
+	PaymentSucceededEventType = "payment.succeeded"
+	PaymentRefundedEventType  = "payment.refunded"
+)
+
+// paymentEventAction is the order action of a payment event type. The order is already in one of
+// the applied statuses when the event is delivered again, e.g. a paid order which has been shipped.
+type paymentEventAction struct {
+	action  domain.OrderAction
+	applied []domain.OrderStatus
+}
+
+var paymentEventActions = map[string]paymentEventAction{
+	PaymentSucceededEventType: {
+		action:  domain.OrderActionPay,
+		applied: []domain.OrderStatus{domain.OrderStatusPaid, domain.OrderStatusShipped, domain.OrderStatusDelivered, domain.OrderStatusRefunded},
+	},
+	PaymentRefundedEventType: {
+		action:  domain.OrderActionRefund,
+		applied: []domain.OrderStatus{domain.OrderStatusRefunded},
+	},
+}
+
+// PaymentEvent is consumed from the payment queue (RUN_MODE=payment-consumer). Type is only read
+// when the message is not a CloudEvents envelope, which carries the type itself.
+type PaymentEvent struct {
+	Type      string `json:"type,omitempty"`
+	OrderId   string `json:"orderId"`
+	PaymentId string `json:"paymentId"`
+}
+
+func newPaymentConsumer() *messaging.SqsConsumer[PaymentEvent] {
+	options := messaging.DefaultSqsConsumerOptions()
+	options.Concurrency = config.ConsumerConcurrency
+	options.MaxReceiveCount = config.ConsumerMaxReceiveCount
+	return messaging.NewSqsConsumer(handlePaymentEvent, options)
+}
+
+// handlePaymentEvent moves the order of a payment event to paid or refunded. Duplicate events are
+// ignored and events without an order are dropped, any other failure is retried on the next
+// delivery until the redrive policy of the queue moves the event to the dead-letter queue.
+func handlePaymentEvent(ctx context.Context, event PaymentEvent, metadata messaging.MessageMetadata) error {
+	eventType := metadata.EventType
+	if eventType == "" {
+		eventType = event.Type
+	}
+	logger := logging.Log(ctx, "PaymentConsumer").
+		WithField("eventType", eventType).
+		WithField("orderId", event.OrderId).
+		WithField("paymentId", event.PaymentId)
+
+	if event.OrderId == "" {
+		return messaging.Permanent(fmt.Errorf("payment event %s has no orderId", metadata.MessageId))
+	}
+
+	eventAction, ok := paymentEventActions[eventType]
+	if !ok {
+		logger.Infof("Ignoring payment event of unknown type")
+		return nil
+	}
+
+	_, err := orderApplication.ChangeOrderStatusCommandHandler.Handle(ctx, usecase.ChangeOrderStatusCommand{Id: event.OrderId, Action: eventAction.action})
+	if eventAction.alreadyApplied(err) {
+		logger.WithError(err).Info("Ignoring payment event which has already been applied")
+		return nil
+	}
+	if err != nil {
+		// an order which is missing or in another status may still catch up, e.g. when events
+		// arrive out of order, otherwise the redrive policy moves the event to the dead-letter queue
+		return err
+	}
+	logger.Info("Payment event handled")
+	return nil
+}
+
+// alreadyApplied reports whether err rejects the action because the order is already past it
+func (a paymentEventAction) alreadyApplied(err error) bool {
+	appError, ok := apperrors.As(err)
+	if !ok || appError.ErrorCode != apperrors.INVALID_STATE_TRANSITION {
+		return false
+	}
+	return slices.Contains(a.applied, domain.OrderStatus(appError.Params["state"]))
+}
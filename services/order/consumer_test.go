@@ -0,0 +1,78 @@
+package main
+
+import (
+	"common"
+	"common/messaging"
+	"context"
+	"order/domain"
+	"order/infrastructure"
+	"testing"
+	"time"
+)
+
+func TestHandlePaymentEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        []domain.OrderAction
+		orderId       string
+		eventType     string
+		wantErr       bool
+		wantPermanent bool
+		wantStatus    domain.OrderStatus
+	}{
+		{name: "pays confirmed order", status: []domain.OrderAction{domain.OrderActionConfirm}, eventType: PaymentSucceededEventType, wantStatus: domain.OrderStatusPaid},
+		{name: "duplicate payment", status: []domain.OrderAction{domain.OrderActionConfirm, domain.OrderActionPay}, eventType: PaymentSucceededEventType, wantStatus: domain.OrderStatusPaid},
+		{name: "payment of shipped order", status: []domain.OrderAction{domain.OrderActionConfirm, domain.OrderActionPay, domain.OrderActionShip}, eventType: PaymentSucceededEventType, wantStatus: domain.OrderStatusShipped},
+		{name: "payment of pending order", eventType: PaymentSucceededEventType, wantErr: true, wantStatus: domain.OrderStatusPending},
+		{name: "refunds paid order", status: []domain.OrderAction{domain.OrderActionConfirm, domain.OrderActionPay}, eventType: PaymentRefundedEventType, wantStatus: domain.OrderStatusRefunded},
+		{name: "duplicate refund", status: []domain.OrderAction{domain.OrderActionConfirm, domain.OrderActionPay, domain.OrderActionRefund}, eventType: PaymentRefundedEventType, wantStatus: domain.OrderStatusRefunded},
+		{name: "refund of cancelled order", status: []domain.OrderAction{domain.OrderActionCancel}, eventType: PaymentRefundedEventType, wantErr: true, wantStatus: domain.OrderStatusCancelled},
+		{name: "unknown type", eventType: "payment.authorized", wantStatus: domain.OrderStatusPending},
+		{name: "unknown order", orderId: "missing", eventType: PaymentSucceededEventType, wantErr: true, wantStatus: domain.OrderStatusPending},
+		{name: "no order id", orderId: "-", eventType: PaymentSucceededEventType, wantErr: true, wantPermanent: true, wantStatus: domain.OrderStatusPending},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := infrastructure.NewInMemoryOrderRepository()
+			orderApplication = newOrderApplication(Config{CommandTimeout: time.Second}, repository, messaging.NewFakePublisher("order"), common.NoTransaction{})
+			t.Cleanup(func() { orderApplication = nil })
+
+			order, err := domain.CreateOrder(ctx, "order-1", "Order", []domain.LineItem{domain.NewLineItem("sku-1", "Item", 1, domain.NewMoney(100, "EUR"))}, 0, domain.NewMoney(0, "EUR"))
+			if err != nil {
+				t.Fatalf("CreateOrder() error = %v", err)
+			}
+			for _, action := range test.status {
+				if err := order.Apply(action); err != nil {
+					t.Fatalf("Apply(%s) error = %v", action, err)
+				}
+			}
+			if err := repository.Save(ctx, order); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			orderId := test.orderId
+			switch orderId {
+			case "":
+				orderId = order.Id
+			case "-":
+				orderId = ""
+			}
+			err = handlePaymentEvent(ctx, PaymentEvent{OrderId: orderId, PaymentId: "payment-1"}, messaging.MessageMetadata{MessageId: "message-1", EventType: test.eventType})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("handlePaymentEvent() error = %v, want error %v", err, test.wantErr)
+			}
+			if messaging.IsPermanent(err) != test.wantPermanent {
+				t.Errorf("handlePaymentEvent() permanent = %v, want %v", messaging.IsPermanent(err), test.wantPermanent)
+			}
+
+			stored, err := repository.GetById(ctx, order.Id)
+			if err != nil {
+				t.Fatalf("GetById() error = %v", err)
+			}
+			if stored.CurrentStatus() != test.wantStatus {
+				t.Errorf("status = %s, want %s", stored.CurrentStatus(), test.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package domain
+
+// LineItem is a quantity of a product ordered at a unit price. Total is computed by the order.
+type LineItem struct {
+	Sku         string `bson:"sku" json:"sku"`
+	Description string `bson:"description" json:"description"`
+	Quantity    int64  `bson:"quantity" json:"quantity"`
+	UnitPrice   Money  `bson:"unitPrice" json:"unitPrice"`
+	Total       Money  `bson:"total" json:"total"`
+}
+
+func NewLineItem(sku string, description string, quantity int64, unitPrice Money) LineItem {
+	return LineItem{
+		Sku:         sku,
+		Description: description,
+		Quantity:    quantity,
+		UnitPrice:   unitPrice,
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money amounts have different currencies")
+	ErrAmountOverflow   = errors.New("money amount is out of range")
+)
+
+// BasisPoints is a rate in hundredths of a percent, 10000 is 100%
+type BasisPoints int64
+
+// Money is an amount in the minor units of its ISO 4217 currency, e.g. cents of EUR
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+// IsValidCurrency reports whether currency looks like an ISO 4217 code
+func IsValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return NewMoney(sum, m.Currency), nil
+}
+
+func (m Money) Subtract(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(NewMoney(-other.Amount, other.Currency))
+}
+
+func (m Money) Multiply(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return NewMoney(product.Int64(), m.Currency), nil
+}
+
+// MultiplyRate returns the share of the amount given by rate, rounded half away from zero to
+// a minor unit
+func (m Money) MultiplyRate(rate BasisPoints) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(int64(rate)))
+	half := big.NewInt(5000)
+	if product.Sign() < 0 {
+		half.Neg(half)
+	}
+	product.Add(product, half).Quo(product, big.NewInt(10000))
+	if !product.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return NewMoney(product.Int64(), m.Currency), nil
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	apperrors "common/errors"
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	eur := func(amount int64) Money { return NewMoney(amount, "EUR") }
+
+	tests := []struct {
+		name      string
+		operation func() (Money, error)
+		want      Money
+		wantErr   error
+	}{
+		{name: "add", operation: func() (Money, error) { return eur(150).Add(eur(250)) }, want: eur(400)},
+		{name: "add negative", operation: func() (Money, error) { return eur(150).Add(eur(-250)) }, want: eur(-100)},
+		{name: "add overflow", operation: func() (Money, error) { return eur(math.MaxInt64).Add(eur(1)) }, wantErr: ErrAmountOverflow},
+		{name: "add underflow", operation: func() (Money, error) { return eur(math.MinInt64).Add(eur(-1)) }, wantErr: ErrAmountOverflow},
+		{name: "add currency mismatch", operation: func() (Money, error) { return eur(1).Add(NewMoney(1, "USD")) }, wantErr: ErrCurrencyMismatch},
+		{name: "subtract", operation: func() (Money, error) { return eur(400).Subtract(eur(150)) }, want: eur(250)},
+		{name: "subtract overflow", operation: func() (Money, error) { return eur(math.MinInt64).Subtract(eur(1)) }, wantErr: ErrAmountOverflow},
+		{name: "subtract min int64", operation: func() (Money, error) { return eur(0).Subtract(eur(math.MinInt64)) }, wantErr: ErrAmountOverflow},
+		{name: "subtract currency mismatch", operation: func() (Money, error) { return eur(1).Subtract(NewMoney(1, "USD")) }, wantErr: ErrCurrencyMismatch},
+		{name: "multiply", operation: func() (Money, error) { return eur(250).Multiply(3) }, want: eur(750)},
+		{name: "multiply largest", operation: func() (Money, error) { return eur(math.MaxInt64 / 2).Multiply(2) }, want: eur(math.MaxInt64 - 1)},
+		{name: "multiply overflow", operation: func() (Money, error) { return eur(math.MaxInt64/2 + 1).Multiply(2) }, wantErr: ErrAmountOverflow},
+		{name: "multiply negative overflow", operation: func() (Money, error) { return eur(math.MinInt64).Multiply(-1) }, wantErr: ErrAmountOverflow},
+		{name: "rate", operation: func() (Money, error) { return eur(1000).MultiplyRate(2000) }, want: eur(200)},
+		{name: "rate rounds half up", operation: func() (Money, error) { return eur(5).MultiplyRate(1000) }, want: eur(1)},
+		{name: "rate rounds down", operation: func() (Money, error) { return eur(14).MultiplyRate(1000) }, want: eur(1)},
+		{name: "rate rounds negative half away from zero", operation: func() (Money, error) { return eur(-5).MultiplyRate(1000) }, want: eur(-1)},
+		{name: "rate rounds negative toward zero", operation: func() (Money, error) { return eur(-14).MultiplyRate(1000) }, want: eur(-1)},
+		{name: "rate of largest amount", operation: func() (Money, error) { return eur(math.MaxInt64).MultiplyRate(MaxTaxRate) }, want: eur(math.MaxInt64)},
+		{name: "rate overflow", operation: func() (Money, error) { return eur(math.MaxInt64).MultiplyRate(MaxTaxRate + 1) }, wantErr: ErrAmountOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.operation()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderPricing(t *testing.T) {
+	tests := []struct {
+		name         string
+		items        []LineItem
+		taxRate      BasisPoints
+		discount     Money
+		wantSubtotal int64
+		wantTax      int64
+		wantTotal    int64
+		wantParam    string
+	}{
+		{name: "tax on discounted subtotal", items: []LineItem{NewLineItem("a", "A", 2, NewMoney(250, "EUR")), NewLineItem("b", "B", 1, NewMoney(100, "EUR"))},
+			taxRate: 2000, discount: NewMoney(100, "EUR"), wantSubtotal: 600, wantTax: 100, wantTotal: 600},
+		{name: "tax is rounded", items: []LineItem{NewLineItem("a", "A", 1, NewMoney(333, "EUR"))},
+			taxRate: 1950, wantSubtotal: 333, wantTax: 65, wantTotal: 398},
+		{name: "line total overflow", items: []LineItem{NewLineItem("a", "A", 2, NewMoney(math.MaxInt64, "EUR"))}, wantParam: "items[0]"},
+		{name: "subtotal overflow", items: []LineItem{NewLineItem("a", "A", 1, NewMoney(math.MaxInt64, "EUR")), NewLineItem("b", "B", 1, NewMoney(1, "EUR"))},
+			wantParam: "items"},
+		{name: "total overflow", items: []LineItem{NewLineItem("a", "A", 1, NewMoney(math.MaxInt64, "EUR"))}, taxRate: 1, wantParam: "taxRate"},
+		{name: "discount above subtotal", items: []LineItem{NewLineItem("a", "A", 1, NewMoney(100, "EUR"))}, discount: NewMoney(101, "EUR"),
+			wantParam: "discount.amount"},
+		{name: "tax rate above maximum", items: []LineItem{NewLineItem("a", "A", 1, NewMoney(100, "EUR"))}, taxRate: MaxTaxRate + 1, wantParam: "taxRate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := CreateOrder(context.Background(), "order-1", "Order", tt.items, tt.taxRate, tt.discount)
+			if tt.wantParam != "" {
+				appErr, ok := apperrors.As(err)
+				if !ok || appErr.ErrorCode != apperrors.INVALID_REQUEST_PARAMETERS || appErr.Params["param"] != tt.wantParam {
+					t.Fatalf("CreateOrder() error = %v, want invalid %s", err, tt.wantParam)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateOrder() error = %v", err)
+			}
+			if order.Subtotal.Amount != tt.wantSubtotal || order.Tax.Amount != tt.wantTax || order.Total.Amount != tt.wantTotal {
+				t.Errorf("subtotal %d tax %d total %d, want %d %d %d",
+					order.Subtotal.Amount, order.Tax.Amount, order.Total.Amount, tt.wantSubtotal, tt.wantTax, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const (
+	OrderCreatedEventType       = "order.created"
+	OrderStatusChangedEventType = "order.status-changed"
+)
+
+// OrderCreated is recorded by CreateOrder
+type OrderCreated struct {
+	OrderId    string      `json:"orderId"`
+	Name       string      `json:"name"`
+	Status     OrderStatus `json:"status"`
+	Total      Money       `json:"total"`
+	OccurredAt time.Time   `json:"occurredAt"`
+}
+
+func (e OrderCreated) EventType() string {
+	return OrderCreatedEventType
+}
+
+// OrderStatusChanged is recorded when an order action changes the order status
+type OrderStatusChanged struct {
+	OrderId    string      `json:"orderId"`
+	Action     OrderAction `json:"action"`
+	From       OrderStatus `json:"from"`
+	To         OrderStatus `json:"to"`
+	OccurredAt time.Time   `json:"occurredAt"`
+}
+
+func (e OrderStatusChanged) EventType() string {
+	return OrderStatusChangedEventType
+}
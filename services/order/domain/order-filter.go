@@ -2,18 +2,25 @@ package domain
 
 import (
 	"common"
+	apperrors "common/errors"
+	"fmt"
 	"net/url"
 	"time"
 )
 
 type OrderFilter struct {
+	// Matches the order id exactly (id=...) or by prefix (id[prefix]=...)
+	//
 	// in: query
 	// required: false
-	Id string `json:"id"`
+	Id *common.StringFilter `json:"id"`
 
+	// Matches the order name exactly (name=...), by prefix (name[prefix]=...), by substring
+	// (name[contains]=...) or exactly ignoring case (name[icase]=...)
+	//
 	// in: query
 	// required: false
-	Name string `json:"name"`
+	Name *common.StringFilter `json:"name"`
 
 	// in: query
 	// required: false
@@ -22,9 +29,38 @@ type OrderFilter struct {
 	// in: query
 	// required: false
 	CreatedTo *time.Time `json:"createdTo"`
+
+	// Matches any of the order statuses, given repeated (status=PAID&status=SHIPPED) or comma
+	// separated (status=PAID,SHIPPED)
+	//
+	// in: query
+	// required: false
+	Status []OrderStatus `json:"status"`
+}
+
+// OrderPageFilterOptions lists the fields orders may be sorted by, mapped to Order storage fields,
+// and the page size limits of order listing
+var OrderPageFilterOptions = common.PageFilterOptions{
+	SortableFields: map[string]string{
+		"id":      common.IdField,
+		"name":    "name",
+		"created": "created",
+		"status":  "status",
+	},
+	DefaultSort:     []common.SortOrder{{Field: common.IdField, Type: common.SortAsc}},
+	DefaultPageSize: 10,
+	MaxPageSize:     100,
 }
 
 func ParseOrderFilter(queryParams url.Values) (*OrderFilter, error) {
+	id, err := common.GetStringFilterFromQueryParams(queryParams, "id", common.MatchExact, common.MatchPrefix)
+	if err != nil {
+		return nil, err
+	}
+	name, err := common.GetStringFilterFromQueryParams(queryParams, "name")
+	if err != nil {
+		return nil, err
+	}
 	createdFrom, err := common.GetTimestampFromQueryParams(queryParams, "createdFrom")
 	if err != nil {
 		return nil, err
@@ -33,10 +69,24 @@ func ParseOrderFilter(queryParams url.Values) (*OrderFilter, error) {
 	if err != nil {
 		return nil, err
 	}
+	if createdFrom != nil && createdTo != nil && createdFrom.After(*createdTo) {
+		return nil, apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("createdFrom %s is after createdTo %s", createdFrom.Format(time.RFC3339), createdTo.Format(time.RFC3339)),
+			"createdFrom", "not after createdTo", nil)
+	}
+	var statuses []OrderStatus
+	for _, value := range common.GetFiltersByName("status", queryParams) {
+		status, err := ParseOrderStatus(value)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
 	return &OrderFilter{
-		Id:          common.GetFilterByName("id", queryParams),
-		Name:        common.GetFilterByName("name", queryParams),
+		Id:          id,
+		Name:        name,
 		CreatedFrom: createdFrom,
 		CreatedTo:   createdTo,
+		Status:      statuses,
 	}, nil
 }
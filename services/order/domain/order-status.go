@@ -0,0 +1,102 @@
+package domain
+
+import (
+	apperrors "common/errors"
+	"fmt"
+	"strings"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "PENDING"
+	OrderStatusConfirmed OrderStatus = "CONFIRMED"
+	OrderStatusPaid      OrderStatus = "PAID"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusRefunded  OrderStatus = "REFUNDED"
+)
+
+var OrderStatuses = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusPaid,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+	OrderStatusRefunded,
+}
+
+// OrderAction changes the status of an order according to orderTransitions
+type OrderAction string
+
+const (
+	OrderActionConfirm OrderAction = "confirm"
+	OrderActionPay     OrderAction = "pay"
+	OrderActionShip    OrderAction = "ship"
+	OrderActionDeliver OrderAction = "deliver"
+	OrderActionCancel  OrderAction = "cancel"
+	OrderActionRefund  OrderAction = "refund"
+)
+
+var OrderActions = []OrderAction{
+	OrderActionConfirm,
+	OrderActionPay,
+	OrderActionShip,
+	OrderActionDeliver,
+	OrderActionCancel,
+	OrderActionRefund,
+}
+
+// orderTransitions lists the actions allowed in each status and the status they lead to.
+// CANCELLED and REFUNDED are final, a paid order can only be refunded.
+var orderTransitions = map[OrderStatus]map[OrderAction]OrderStatus{
+	OrderStatusPending: {
+		OrderActionConfirm: OrderStatusConfirmed,
+		OrderActionCancel:  OrderStatusCancelled,
+	},
+	OrderStatusConfirmed: {
+		OrderActionPay:    OrderStatusPaid,
+		OrderActionCancel: OrderStatusCancelled,
+	},
+	OrderStatusPaid: {
+		OrderActionShip:   OrderStatusShipped,
+		OrderActionRefund: OrderStatusRefunded,
+	},
+	OrderStatusShipped: {
+		OrderActionDeliver: OrderStatusDelivered,
+	},
+	OrderStatusDelivered: {
+		OrderActionRefund: OrderStatusRefunded,
+	},
+}
+
+// CanTransition reports whether the action is allowed in the status
+func (s OrderStatus) CanTransition(action OrderAction) bool {
+	_, ok := orderTransitions[s][action]
+	return ok
+}
+
+// IsFinal reports whether no action is allowed in the status
+func (s OrderStatus) IsFinal() bool {
+	return len(orderTransitions[s]) == 0
+}
+
+func ParseOrderStatus(value string) (OrderStatus, error) {
+	for _, status := range OrderStatuses {
+		if strings.EqualFold(value, string(status)) {
+			return status, nil
+		}
+	}
+	return "", apperrors.InvalidRequestParameterWithValidation(
+		fmt.Sprintf("Order status '%s' is not supported", value), "status", fmt.Sprintf("one of %s", joinOrderStatuses()), nil)
+}
+
+func joinOrderStatuses() string {
+	names := make([]string, 0, len(OrderStatuses))
+	for _, status := range OrderStatuses {
+		names = append(names, string(status))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	apperrors "common/errors"
+	"testing"
+)
+
+func TestOrderTransitions(t *testing.T) {
+	// want lists every allowed transition, all other status and action combinations are rejected
+	want := map[OrderStatus]map[OrderAction]OrderStatus{
+		OrderStatusPending:   {OrderActionConfirm: OrderStatusConfirmed, OrderActionCancel: OrderStatusCancelled},
+		OrderStatusConfirmed: {OrderActionPay: OrderStatusPaid, OrderActionCancel: OrderStatusCancelled},
+		OrderStatusPaid:      {OrderActionShip: OrderStatusShipped, OrderActionRefund: OrderStatusRefunded},
+		OrderStatusShipped:   {OrderActionDeliver: OrderStatusDelivered},
+		OrderStatusDelivered: {OrderActionRefund: OrderStatusRefunded},
+	}
+	for _, status := range OrderStatuses {
+		for _, action := range OrderActions {
+			t.Run(string(status)+" "+string(action), func(t *testing.T) {
+				next, allowed := want[status][action]
+				if got := status.CanTransition(action); got != allowed {
+					t.Errorf("CanTransition() = %v, want %v", got, allowed)
+				}
+
+				order := &Order{Id: "order-1", Status: status}
+				err := order.Apply(action)
+				if !allowed {
+					if !apperrors.Is(err, apperrors.INVALID_STATE_TRANSITION) {
+						t.Fatalf("Apply() error = %v, want INVALID_STATE_TRANSITION", err)
+					}
+					if appErr, _ := apperrors.As(err); appErr.Params["state"] != string(status) || appErr.Params["action"] != string(action) {
+						t.Errorf("Apply() params = %v", appErr.Params)
+					}
+					if order.Status != status || len(order.Events()) != 0 {
+						t.Errorf("rejected Apply() changed the order to %s with events %v", order.Status, order.Events())
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Apply() error = %v", err)
+				}
+				if order.Status != next {
+					t.Errorf("Status = %s, want %s", order.Status, next)
+				}
+				events := order.Events()
+				if len(events) != 1 {
+					t.Fatalf("Events() = %v, want one OrderStatusChanged", events)
+				}
+				changed, ok := events[0].(OrderStatusChanged)
+				if !ok || changed.From != status || changed.To != next || changed.Action != action {
+					t.Errorf("event = %+v, want %s from %s to %s", events[0], action, status, next)
+				}
+			})
+		}
+	}
+}
+
+func TestOrderStatusIsFinal(t *testing.T) {
+	tests := []struct {
+		status OrderStatus
+		want   bool
+	}{
+		{OrderStatusPending, false},
+		{OrderStatusConfirmed, false},
+		{OrderStatusPaid, false},
+		{OrderStatusShipped, false},
+		{OrderStatusDelivered, false},
+		{OrderStatusCancelled, true},
+		{OrderStatusRefunded, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.IsFinal(); got != tt.want {
+				t.Errorf("IsFinal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderWithoutStatusIsPending(t *testing.T) {
+	order := &Order{Id: "order-1"}
+	if status := order.CurrentStatus(); status != OrderStatusPending {
+		t.Fatalf("CurrentStatus() = %s, want %s", status, OrderStatusPending)
+	}
+	if err := order.Confirm(); err != nil || order.Status != OrderStatusConfirmed {
+		t.Errorf("Confirm() = %v, status %s, want %s", err, order.Status, OrderStatusConfirmed)
+	}
+}
+
+func TestParseOrderStatus(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    OrderStatus
+		wantErr bool
+	}{
+		{"PAID", OrderStatusPaid, false},
+		{"cancelled", OrderStatusCancelled, false},
+		{"archived", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOrderStatus(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrderStatus() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOrderStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
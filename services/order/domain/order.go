@@ -1,26 +1,199 @@
 package domain
 
 import (
+	apperrors "common/errors"
+	"common/messaging"
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"time"
 )
 
+// MaxTaxRate is the highest accepted tax rate, 100%
+const MaxTaxRate BasisPoints = 10000
+
+// Order aggregate. Version is 0 until the order is saved for the first time, it is incremented
+// by OrderRepository.Save and used for optimistic locking. Id, Name, Version and Created have no
+// json tags, the API has always returned them with their Go names.
 type Order struct {
 	Id      string    `bson:"_id"`
 	Name    string    `bson:"name"`
 	Version int       `bson:"version"`
 	Created time.Time `bson:"created"`
+	// Status is changed only through the order actions, see orderTransitions
+	Status OrderStatus `bson:"status" json:"status"`
+
+	// Items all have prices in Currency, the amounts below are computed from them by the order
+	Items    []LineItem  `bson:"items" json:"items"`
+	Currency string      `bson:"currency" json:"currency"`
+	TaxRate  BasisPoints `bson:"taxRate" json:"taxRate"`
+	Subtotal Money       `bson:"subtotal" json:"subtotal"`
+	Discount Money       `bson:"discount" json:"discount"`
+	Tax      Money       `bson:"tax" json:"tax"`
+	Total    Money       `bson:"total" json:"total"`
+
+	// EventRecorder holds the events of the order until they are pulled by the command handler
+	messaging.EventRecorder `bson:"-" json:"-"`
 }
 
-func CreateOrder(ctx context.Context, id string, name string) (*Order, error) {
+// CreateOrder creates a pending order of at least one item. All prices and the discount must be in
+// the same currency, tax is charged on the subtotal reduced by the discount.
+func CreateOrder(ctx context.Context, id string, name string, items []LineItem, taxRate BasisPoints, discount Money) (*Order, error) {
 	if id == "" {
 		id = uuid.NewString()
 	}
-	return &Order{
+	order := &Order{
 		Id:      id,
 		Name:    name,
-		Version: 1,
+		Version: 0,
 		Created: time.Now(),
-	}, nil
+		Status:  OrderStatusPending,
+		TaxRate: taxRate,
+	}
+	if err := order.price(items, discount); err != nil {
+		return nil, err
+	}
+	order.Record(OrderCreated{
+		OrderId:    order.Id,
+		Name:       order.Name,
+		Status:     order.Status,
+		Total:      order.Total,
+		OccurredAt: order.Created,
+	})
+	return order, nil
+}
+
+// price validates the items and computes the line and order totals
+func (o *Order) price(items []LineItem, discount Money) error {
+	if len(items) == 0 {
+		return apperrors.InvalidRequestParameterWithValidation("Order has no items", "items", "at least one item", nil)
+	}
+	currency := items[0].UnitPrice.Currency
+	if !IsValidCurrency(currency) {
+		return apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("Currency '%s' is not an ISO 4217 code", currency), "items[0].unitPrice.currency", "ISO 4217 currency code", nil)
+	}
+	if o.TaxRate < 0 || o.TaxRate > MaxTaxRate {
+		return apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("Tax rate %d is out of range", o.TaxRate), "taxRate", fmt.Sprintf("between 0 and %d basis points", MaxTaxRate), nil)
+	}
+
+	subtotal := NewMoney(0, currency)
+	pricedItems := make([]LineItem, 0, len(items))
+	for i, item := range items {
+		if item.Sku == "" {
+			return apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Item %d has no sku", i), fmt.Sprintf("items[%d].sku", i), "not empty", nil)
+		}
+		if item.Quantity < 1 {
+			return apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Item %s has quantity %d", item.Sku, item.Quantity), fmt.Sprintf("items[%d].quantity", i), "positive", nil)
+		}
+		if item.UnitPrice.Currency != currency {
+			return apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Item %s is priced in %s, expected %s", item.Sku, item.UnitPrice.Currency, currency), fmt.Sprintf("items[%d].unitPrice.currency", i), "single currency", nil)
+		}
+		if item.UnitPrice.Amount < 0 {
+			return apperrors.InvalidRequestParameterWithValidation(
+				fmt.Sprintf("Item %s has negative unit price %d", item.Sku, item.UnitPrice.Amount), fmt.Sprintf("items[%d].unitPrice.amount", i), "not negative", nil)
+		}
+		total, err := item.UnitPrice.Multiply(item.Quantity)
+		if err != nil {
+			return amountOutOfRange(fmt.Sprintf("items[%d]", i), err)
+		}
+		if subtotal, err = subtotal.Add(total); err != nil {
+			return amountOutOfRange("items", err)
+		}
+		item.Total = total
+		pricedItems = append(pricedItems, item)
+	}
+
+	if discount.Currency == "" && discount.Amount == 0 {
+		discount = NewMoney(0, currency)
+	}
+	if discount.Currency != currency {
+		return apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("Discount is given in %s, expected %s", discount.Currency, currency), "discount.currency", "single currency", nil)
+	}
+	if discount.Amount < 0 || discount.Amount > subtotal.Amount {
+		return apperrors.InvalidRequestParameterWithValidation(
+			fmt.Sprintf("Discount %d is out of range of subtotal %d", discount.Amount, subtotal.Amount), "discount.amount", "between 0 and subtotal", nil)
+	}
+
+	taxable, err := subtotal.Subtract(discount)
+	if err != nil {
+		return amountOutOfRange("discount", err)
+	}
+	tax, err := taxable.MultiplyRate(o.TaxRate)
+	if err != nil {
+		return amountOutOfRange("taxRate", err)
+	}
+	total, err := taxable.Add(tax)
+	if err != nil {
+		return amountOutOfRange("taxRate", err)
+	}
+
+	o.Items = pricedItems
+	o.Currency = currency
+	o.Subtotal = subtotal
+	o.Discount = discount
+	o.Tax = tax
+	o.Total = total
+	return nil
+}
+
+func amountOutOfRange(param string, cause error) error {
+	return apperrors.InvalidRequestParameterWithValidation("Order amount is out of range", param, "amount in range", cause)
+}
+
+func (o *Order) Confirm() error {
+	return o.Apply(OrderActionConfirm)
+}
+
+func (o *Order) Pay() error {
+	return o.Apply(OrderActionPay)
+}
+
+func (o *Order) Ship() error {
+	return o.Apply(OrderActionShip)
+}
+
+func (o *Order) Deliver() error {
+	return o.Apply(OrderActionDeliver)
+}
+
+func (o *Order) Cancel() error {
+	return o.Apply(OrderActionCancel)
+}
+
+func (o *Order) Refund() error {
+	return o.Apply(OrderActionRefund)
+}
+
+// Apply changes the status by the action, an INVALID_STATE_TRANSITION error is returned when the
+// action is not allowed in the current status
+func (o *Order) Apply(action OrderAction) error {
+	status := o.CurrentStatus()
+	next, ok := orderTransitions[status][action]
+	if !ok {
+		return apperrors.InvalidStateTransition(
+			fmt.Sprintf("Order %s can not %s in status %s", o.Id, action, status), string(status), string(action))
+	}
+	o.Status = next
+	o.Record(OrderStatusChanged{
+		OrderId:    o.Id,
+		Action:     action,
+		From:       status,
+		To:         next,
+		OccurredAt: time.Now(),
+	})
+	return nil
+}
+
+// CurrentStatus returns the order status, orders stored before statuses were introduced are pending
+func (o *Order) CurrentStatus() OrderStatus {
+	if o.Status == "" {
+		return OrderStatusPending
+	}
+	return o.Status
 }
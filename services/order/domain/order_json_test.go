@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestJsonFieldNames(t *testing.T) {
+	price := NewMoney(250, "EUR")
+	order, err := CreateOrder(context.Background(), "order-1", "Order", []LineItem{NewLineItem("sku-1", "Item", 2, price)}, 2000, NewMoney(0, "EUR"))
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{"money", price, []string{"amount", "currency"}},
+		{"line item", order.Items[0], []string{"description", "quantity", "sku", "total", "unitPrice"}},
+		{"order", order, []string{"Created", "Id", "Name", "Version", "currency", "discount", "items", "status", "subtotal", "tax", "taxRate", "total"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			got := make([]string, 0, len(fields))
+			for name := range fields {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,5 +8,6 @@ import (
 type OrderRepository interface {
 	GetById(ctx context.Context, id string) (*Order, error)
 	GetAll(ctx context.Context, merchantFilter *OrderFilter, pageFilter *common.PageFilter) (*common.Paginated[Order], error)
+	GetAllByCursor(ctx context.Context, orderFilter *OrderFilter, pageFilter *common.CursorPageFilter) (*common.CursorPaginated[Order], error)
 	Save(ctx context.Context, order *Order) error
 }
@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"common"
+	"order/domain"
+	"time"
+)
+
+// orderFieldValue returns the value of a stored order field in the form it is compared by Mongo,
+// nil is returned for unknown fields
+func orderFieldValue(order *domain.Order, field string) interface{} {
+	switch field {
+	case "_id":
+		return order.Id
+	case "name":
+		return order.Name
+	case "version":
+		return int64(order.Version)
+	case "created":
+		return order.Created.Truncate(time.Millisecond).UTC()
+	case "status":
+		return string(order.CurrentStatus())
+	}
+	return nil
+}
+
+func orderCursorKeys(sortOrders []common.SortOrder) func(order *domain.Order) []common.CursorKey {
+	return func(order *domain.Order) []common.CursorKey {
+		keys := make([]common.CursorKey, 0, len(sortOrders))
+		for _, sortOrder := range sortOrders {
+			keys = append(keys, common.CursorKey{
+				Field: sortOrder.Field,
+				Value: orderFieldValue(order, sortOrder.Field),
+			})
+		}
+		return keys
+	}
+}
+
+// compareOrders compares orders by sortOrders, the result is negative when a is placed before b
+func compareOrders(a *domain.Order, b *domain.Order, sortOrders []common.SortOrder) int {
+	for _, sortOrder := range sortOrders {
+		result := common.CompareCursorValues(orderFieldValue(a, sortOrder.Field), orderFieldValue(b, sortOrder.Field))
+		if result != 0 {
+			return result * sortOrder.GetSortTypeInt()
+		}
+	}
+	return 0
+}
+
+// compareWithCursor compares the order with the cursor keys, the result is negative when the order
+// is placed before the cursor boundary
+func compareWithCursor(order *domain.Order, sortOrders []common.SortOrder, keys []common.CursorKey) int {
+	for i, sortOrder := range sortOrders {
+		result := common.CompareCursorValues(orderFieldValue(order, sortOrder.Field), keys[i].Value)
+		if result != 0 {
+			return result * sortOrder.GetSortTypeInt()
+		}
+	}
+	return 0
+}
+
+func reverseSortOrders(sortOrders []common.SortOrder) []common.SortOrder {
+	reversed := make([]common.SortOrder, 0, len(sortOrders))
+	for _, sortOrder := range sortOrders {
+		sortType := common.SortDesc
+		if sortOrder.Type == common.SortDesc {
+			sortType = common.SortAsc
+		}
+		reversed = append(reversed, common.SortOrder{Field: sortOrder.Field, Type: sortType})
+	}
+	return reversed
+}
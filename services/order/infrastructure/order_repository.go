@@ -6,6 +6,7 @@ import (
 	"common/logging"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/apex/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -47,35 +48,7 @@ func (r *OrderRepositoryImpl) GetAll(ctx context.Context, merchantFilter *domain
 	logger := r.getLogger(ctx)
 	logger.Infof("GetAll")
 
-	filter := bson.M{}
-
-	if len(merchantFilter.Id) > 0 {
-		filter["_id"] = primitive.Regex{
-			Pattern: merchantFilter.Id,
-		}
-	}
-	if len(merchantFilter.Name) > 0 {
-		filter["name"] = primitive.Regex{
-			Pattern: merchantFilter.Name,
-		}
-	}
-	if merchantFilter.CreatedFrom != nil && merchantFilter.CreatedTo != nil {
-		filter["created"] = bson.M{
-			"$gte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedFrom),
-			"$lte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedTo),
-		}
-	} else {
-		if merchantFilter.CreatedFrom != nil {
-			filter["created"] = bson.M{
-				"$gte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedFrom),
-			}
-		}
-		if merchantFilter.CreatedTo != nil {
-			filter["created"] = bson.M{
-				"$lte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedTo),
-			}
-		}
-	}
+	filter := orderFilterDocument(merchantFilter)
 
 	pageSize := pageFilter.PageSize
 	skip := pageFilter.GetSkip()
@@ -83,12 +56,16 @@ func (r *OrderRepositoryImpl) GetAll(ctx context.Context, merchantFilter *domain
 	opt := &options.FindOptions{
 		Limit: &pageSize,
 		Skip:  &skip,
-		Sort:  bson.D{{pageFilter.SortField, pageFilter.GetSortTypeInt()}},
+		Sort:  sortDocument(pageFilter.Sort),
 	}
 
-	documentCount, err := r.mongoCollection.CountDocuments(ctx, filter)
-	if err != nil {
-		return nil, apperrors.InternalServerError("Failed to get document count", err)
+	var documentCount int64
+	if pageFilter.IncludeTotal {
+		var err error
+		documentCount, err = r.mongoCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, apperrors.InternalServerError("Failed to get document count", err)
+		}
 	}
 
 	cursor, err := r.mongoCollection.Find(ctx, filter, opt)
@@ -104,23 +81,194 @@ func (r *OrderRepositoryImpl) GetAll(ctx context.Context, merchantFilter *domain
 		}
 	}
 
+	if !pageFilter.IncludeTotal {
+		return common.NewPaginatedWithoutTotal[domain.Order](encryptedDatas, pageFilter.PageSize, pageFilter.Page), nil
+	}
 	return common.NewPaginated[domain.Order](encryptedDatas, documentCount, pageFilter.PageSize, pageFilter.Page), nil
 }
 
+// Save inserts a new order (Version 0) or replaces the stored one only if it still has the version
+// the order was loaded with. On success order.Version is incremented, a lost update is reported as
+// apperrors.CONCURRENT_MODIFICATION.
 func (r *OrderRepositoryImpl) Save(ctx context.Context, order *domain.Order) error {
 	logger := r.getLogger(ctx)
 	logger.Infof("Save %s", order.Id)
 
-	order.Version = order.Version + 1
+	expectedVersion := order.Version
+	order.Version = expectedVersion + 1
 
-	_, err := r.mongoCollection.ReplaceOne(ctx, bson.M{"_id": order.Id}, order, options.Replace().SetUpsert(true))
+	err := r.save(ctx, order, expectedVersion)
 	if err != nil {
+		order.Version = expectedVersion
 		return err
 	}
 
 	return nil
 }
 
+func (r *OrderRepositoryImpl) save(ctx context.Context, order *domain.Order, expectedVersion int) error {
+	if expectedVersion == 0 {
+		_, err := r.mongoCollection.InsertOne(ctx, order)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return apperrors.EntityAlreadyExist("Order already exists", "id", order.Id, err)
+			}
+			return apperrors.InternalServerError("Failed to insert order", err)
+		}
+		return nil
+	}
+
+	result, err := r.mongoCollection.ReplaceOne(ctx, bson.M{"_id": order.Id, "version": expectedVersion}, order)
+	if err != nil {
+		return apperrors.InternalServerError("Failed to replace order", err)
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.ConcurrentModification(
+			fmt.Sprintf("Order %s was not found with version %d", order.Id, expectedVersion), "id", order.Id, nil)
+	}
+	return nil
+}
+
+// BackfillStatus stores PENDING as status of orders saved before statuses were introduced. Mongo
+// compares values of different types never by $gt or $lt, so sorting and keyset pagination by
+// status require every order to have one.
+func (r *OrderRepositoryImpl) BackfillStatus(ctx context.Context) (int64, error) {
+	result, err := r.mongoCollection.UpdateMany(ctx,
+		bson.M{"status": bson.M{"$in": bson.A{nil, ""}}},
+		bson.M{"$set": bson.M{"status": domain.OrderStatusPending}})
+	if err != nil {
+		return 0, apperrors.InternalServerError("Failed to backfill order status", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *OrderRepositoryImpl) GetAllByCursor(ctx context.Context, orderFilter *domain.OrderFilter, pageFilter *common.CursorPageFilter) (*common.CursorPaginated[domain.Order], error) {
+	logger := r.getLogger(ctx)
+	logger.Infof("GetAllByCursor")
+
+	sortOrders := pageFilter.SortWithTieBreaker()
+	backward := pageFilter.IsBackward()
+	fetchOrders := sortOrders
+	if backward {
+		fetchOrders = reverseSortOrders(sortOrders)
+	}
+
+	filter := orderFilterDocument(orderFilter)
+	if pageFilter.Cursor != nil {
+		filter = bson.M{"$and": bson.A{filter, keysetCondition(sortOrders, pageFilter.Cursor.Keys, backward)}}
+	}
+
+	limit := pageFilter.PageSize + 1
+	opt := &options.FindOptions{
+		Limit: &limit,
+		Sort:  sortDocument(fetchOrders),
+	}
+
+	cursor, err := r.mongoCollection.Find(ctx, filter, opt)
+	if err != nil {
+		return nil, apperrors.InternalServerError("Failed to get all orders", err)
+	}
+	defer cursor.Close(ctx)
+	var orders []*domain.Order
+	for cursor.Next(ctx) {
+		var order domain.Order
+		if err := cursor.Decode(&order); err == nil {
+			orders = append(orders, &order)
+		}
+	}
+
+	hasMore := int64(len(orders)) > pageFilter.PageSize
+	if hasMore {
+		orders = orders[:pageFilter.PageSize]
+	}
+	if backward {
+		reverseOrders(orders)
+	}
+
+	return common.NewCursorPaginated[domain.Order](orders, pageFilter, hasMore, orderCursorKeys(sortOrders)), nil
+}
+
+func orderFilterDocument(merchantFilter *domain.OrderFilter) bson.M {
+	filter := bson.M{}
+
+	if merchantFilter.Id != nil {
+		filter["_id"] = stringFilterCondition(merchantFilter.Id)
+	}
+	if merchantFilter.Name != nil {
+		filter["name"] = stringFilterCondition(merchantFilter.Name)
+	}
+	if merchantFilter.CreatedFrom != nil && merchantFilter.CreatedTo != nil {
+		filter["created"] = bson.M{
+			"$gte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedFrom),
+			"$lte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedTo),
+		}
+	} else {
+		if merchantFilter.CreatedFrom != nil {
+			filter["created"] = bson.M{
+				"$gte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedFrom),
+			}
+		}
+		if merchantFilter.CreatedTo != nil {
+			filter["created"] = bson.M{
+				"$lte": primitive.NewDateTimeFromTime(*merchantFilter.CreatedTo),
+			}
+		}
+	}
+	if len(merchantFilter.Status) > 0 {
+		statuses := bson.A{}
+		for _, status := range merchantFilter.Status {
+			statuses = append(statuses, status)
+			// orders stored before statuses were introduced have none and are pending
+			if status == domain.OrderStatusPending {
+				statuses = append(statuses, nil)
+			}
+		}
+		filter["status"] = bson.M{"$in": statuses}
+	}
+
+	return filter
+}
+
+func sortDocument(sortOrders []common.SortOrder) bson.D {
+	sort := bson.D{}
+	for _, sortOrder := range sortOrders {
+		sort = append(sort, bson.E{Key: sortOrder.Field, Value: sortOrder.GetSortTypeInt()})
+	}
+	return sort
+}
+
+// keysetCondition selects documents placed after the cursor keys in sortOrders, or before them
+// when backward: (k1 > v1) or (k1 = v1 and k2 > v2) or ...
+func keysetCondition(sortOrders []common.SortOrder, keys []common.CursorKey, backward bool) bson.M {
+	conditions := bson.A{}
+	for i, sortOrder := range sortOrders {
+		condition := bson.M{}
+		for j := 0; j < i; j++ {
+			condition[keys[j].Field] = keys[j].Value
+		}
+		operator := "$gt"
+		if (sortOrder.Type == common.SortDesc) != backward {
+			operator = "$lt"
+		}
+		condition[sortOrder.Field] = bson.M{operator: keys[i].Value}
+		conditions = append(conditions, condition)
+	}
+	return bson.M{"$or": conditions}
+}
+
+// stringFilterCondition converts the filter to a Mongo condition. Exact matches use equality,
+// other modes an escaped regex which is anchored (and so can use an index) for prefix matches.
+func stringFilterCondition(filter *common.StringFilter) interface{} {
+	if filter.Mode == common.MatchExact || filter.Mode == "" {
+		return filter.Value
+	}
+	pattern, regexOptions := filter.Pattern()
+	return primitive.Regex{
+		Pattern: pattern,
+		Options: regexOptions,
+	}
+}
+
 func (r *OrderRepositoryImpl) getLogger(ctx context.Context) *log.Entry {
 	return logging.Log(ctx, "OrderRepository")
 }
@@ -0,0 +1,220 @@
+package infrastructure
+
+import (
+	"common"
+	"common/errors"
+	"common/logging"
+	"common/messaging"
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"order/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InMemoryOrderRepository is a thread-safe domain.OrderRepository keeping orders in memory.
+// It mirrors the behavior of OrderRepositoryImpl and is meant for tests and local runs.
+type InMemoryOrderRepository struct {
+	mu     sync.RWMutex
+	orders map[string]*domain.Order
+	// insertion order of ids, used as natural order like in a Mongo collection
+	ids []string
+}
+
+func NewInMemoryOrderRepository() *InMemoryOrderRepository {
+	return &InMemoryOrderRepository{
+		orders: map[string]*domain.Order{},
+	}
+}
+
+func (r *InMemoryOrderRepository) GetById(ctx context.Context, id string) (*domain.Order, error) {
+	logger := r.getLogger(ctx)
+	logger.Infof("GetById id: %s", id)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, apperrors.EntityNotFound("Order not found", "id", id, nil)
+	}
+	return copyOrder(order), nil
+}
+
+func (r *InMemoryOrderRepository) GetAll(ctx context.Context, orderFilter *domain.OrderFilter, pageFilter *common.PageFilter) (*common.Paginated[domain.Order], error) {
+	logger := r.getLogger(ctx)
+	logger.Infof("GetAll")
+
+	matches := newOrderMatcher(orderFilter)
+
+	filtered := r.findMatching(matches)
+
+	sortOrdersBy(filtered, pageFilter.Sort)
+
+	total := int64(len(filtered))
+	var page []*domain.Order
+	skip := pageFilter.GetSkip()
+	if skip < total {
+		end := total
+		if pageFilter.PageSize > 0 && skip+pageFilter.PageSize < total {
+			end = skip + pageFilter.PageSize
+		}
+		page = filtered[skip:end]
+	}
+
+	if !pageFilter.IncludeTotal {
+		return common.NewPaginatedWithoutTotal[domain.Order](page, pageFilter.PageSize, pageFilter.Page), nil
+	}
+	return common.NewPaginated[domain.Order](page, total, pageFilter.PageSize, pageFilter.Page), nil
+}
+
+func (r *InMemoryOrderRepository) GetAllByCursor(ctx context.Context, orderFilter *domain.OrderFilter, pageFilter *common.CursorPageFilter) (*common.CursorPaginated[domain.Order], error) {
+	logger := r.getLogger(ctx)
+	logger.Infof("GetAllByCursor")
+
+	matches := newOrderMatcher(orderFilter)
+	sortOrders := pageFilter.SortWithTieBreaker()
+	backward := pageFilter.IsBackward()
+	fetchOrders := sortOrders
+	if backward {
+		fetchOrders = reverseSortOrders(sortOrders)
+	}
+
+	filtered := r.findMatching(func(order *domain.Order) bool {
+		if !matches(order) {
+			return false
+		}
+		if pageFilter.Cursor == nil {
+			return true
+		}
+		position := compareWithCursor(order, sortOrders, pageFilter.Cursor.Keys)
+		return (!backward && position > 0) || (backward && position < 0)
+	})
+	sortOrdersBy(filtered, fetchOrders)
+
+	hasMore := int64(len(filtered)) > pageFilter.PageSize
+	if hasMore {
+		filtered = filtered[:pageFilter.PageSize]
+	}
+	if backward {
+		reverseOrders(filtered)
+	}
+
+	return common.NewCursorPaginated[domain.Order](filtered, pageFilter, hasMore, orderCursorKeys(sortOrders)), nil
+}
+
+func (r *InMemoryOrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	logger := r.getLogger(ctx)
+	logger.Infof("Save %s", order.Id)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expectedVersion := order.Version
+	stored, exists := r.orders[order.Id]
+	if expectedVersion == 0 {
+		if exists {
+			return apperrors.EntityAlreadyExist("Order already exists", "id", order.Id, nil)
+		}
+		r.ids = append(r.ids, order.Id)
+	} else if !exists || stored.Version != expectedVersion {
+		return apperrors.ConcurrentModification(
+			fmt.Sprintf("Order %s was not found with version %d", order.Id, expectedVersion), "id", order.Id, nil)
+	}
+
+	order.Version = expectedVersion + 1
+	r.orders[order.Id] = toStoredOrder(order)
+	return nil
+}
+
+func (r *InMemoryOrderRepository) getLogger(ctx context.Context) *log.Entry {
+	return logging.Log(ctx, "InMemoryOrderRepository")
+}
+
+// findMatching returns copies of the stored orders accepted by matches in natural order
+func (r *InMemoryOrderRepository) findMatching(matches func(order *domain.Order) bool) []*domain.Order {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*domain.Order
+	for _, id := range r.ids {
+		order := r.orders[id]
+		if matches(order) {
+			found = append(found, copyOrder(order))
+		}
+	}
+	return found
+}
+
+func newOrderMatcher(filter *domain.OrderFilter) func(order *domain.Order) bool {
+	createdFrom := truncateToStoredPrecision(filter.CreatedFrom)
+	createdTo := truncateToStoredPrecision(filter.CreatedTo)
+
+	return func(order *domain.Order) bool {
+		if filter.Id != nil && !filter.Id.Matches(order.Id) {
+			return false
+		}
+		if filter.Name != nil && !filter.Name.Matches(order.Name) {
+			return false
+		}
+		if createdFrom != nil && order.Created.Before(*createdFrom) {
+			return false
+		}
+		if createdTo != nil && order.Created.After(*createdTo) {
+			return false
+		}
+		if len(filter.Status) > 0 && !containsStatus(filter.Status, order.CurrentStatus()) {
+			return false
+		}
+		return true
+	}
+}
+
+// sortOrdersBy sorts the same way Mongo does, orders with equal values (or an unknown field) keep
+// their natural order
+func sortOrdersBy(orders []*domain.Order, sortOrders []common.SortOrder) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		return compareOrders(orders[i], orders[j], sortOrders) < 0
+	})
+}
+
+// toStoredOrder copies the order the way it round trips through Mongo, which keeps timestamps
+// with millisecond precision
+func toStoredOrder(order *domain.Order) *domain.Order {
+	stored := copyOrder(order)
+	stored.Created = stored.Created.Truncate(time.Millisecond)
+	return stored
+}
+
+func truncateToStoredPrecision(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	truncated := t.Truncate(time.Millisecond)
+	return &truncated
+}
+
+func containsStatus(statuses []domain.OrderStatus, status domain.OrderStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func reverseOrders(orders []*domain.Order) {
+	for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+		orders[i], orders[j] = orders[j], orders[i]
+	}
+}
+
+// copyOrder copies the stored fields of the order, recorded events are not stored
+func copyOrder(order *domain.Order) *domain.Order {
+	copied := *order
+	copied.Items = append([]domain.LineItem(nil), order.Items...)
+	copied.EventRecorder = messaging.EventRecorder{}
+	return &copied
+}
@@ -0,0 +1,13 @@
+package infrastructure
+
+import (
+	"order/domain"
+	"order/infrastructure/repositorytest"
+	"testing"
+)
+
+func TestInMemoryOrderRepository(t *testing.T) {
+	repositorytest.RunOrderRepositoryContract(t, func(t *testing.T) domain.OrderRepository {
+		return NewInMemoryOrderRepository()
+	})
+}
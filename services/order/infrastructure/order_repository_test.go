@@ -0,0 +1,43 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"order/domain"
+	"order/infrastructure/repositorytest"
+	"os"
+	"testing"
+	"time"
+)
+
+// MongoTestUrlEnv holds the connection string of a Mongo instance the contract runs against, e.g.
+// mongodb://localhost:27017. The test is skipped when it is not set.
+const MongoTestUrlEnv = "MONGO_TEST_URL"
+
+func TestOrderRepositoryImpl(t *testing.T) {
+	mongoUrl := os.Getenv(MongoTestUrlEnv)
+	if mongoUrl == "" {
+		t.Skipf("%s is not set", MongoTestUrlEnv)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoUrl))
+	if err != nil {
+		t.Fatalf("Connecting to %s failed: %v", mongoUrl, err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("Pinging %s failed: %v", mongoUrl, err)
+	}
+
+	cases := 0
+	repositorytest.RunOrderRepositoryContract(t, func(t *testing.T) domain.OrderRepository {
+		cases++
+		database := fmt.Sprintf("order_contract_%d_%d", time.Now().UnixNano(), cases)
+		t.Cleanup(func() { client.Database(database).Drop(context.Background()) })
+		return NewOrderRepository(client, database)
+	})
+}
@@ -0,0 +1,421 @@
+// Package repositorytest holds the behavioral contract every domain.OrderRepository implementation
+// must satisfy. Implementations call RunOrderRepositoryContract from their own tests.
+package repositorytest
+
+import (
+	"common"
+	apperrors "common/errors"
+	"common/logging"
+	"context"
+	"fmt"
+	"order/domain"
+	"testing"
+	"time"
+)
+
+// RepositoryFactory returns an empty repository, it is called once per contract case
+type RepositoryFactory func(t *testing.T) domain.OrderRepository
+
+func RunOrderRepositoryContract(t *testing.T, newRepository RepositoryFactory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, repository domain.OrderRepository)
+	}{
+		{"SaveAndGetById", testSaveAndGetById},
+		{"SaveKeepsItemsAndTotals", testSaveKeepsItemsAndTotals},
+		{"GetByIdNotFound", testGetByIdNotFound},
+		{"SaveIncrementsVersion", testSaveIncrementsVersion},
+		{"SaveRejectsStaleVersion", testSaveRejectsStaleVersion},
+		{"SaveRejectsExistingIdForNewOrder", testSaveRejectsExistingIdForNewOrder},
+		{"SaveRejectsUpdateOfMissingOrder", testSaveRejectsUpdateOfMissingOrder},
+		{"GetAllFiltersByIdAndName", testGetAllFiltersByIdAndName},
+		{"GetAllTreatsFilterValuesLiterally", testGetAllTreatsFilterValuesLiterally},
+		{"GetAllFiltersByCreatedRange", testGetAllFiltersByCreatedRange},
+		{"GetAllFiltersByStatus", testGetAllFiltersByStatus},
+		{"GetAllSortsAndPages", testGetAllSortsAndPages},
+		{"GetAllSortsByMultipleFields", testGetAllSortsByMultipleFields},
+		{"GetAllByCursorWalksForwardAndBackward", testGetAllByCursorWalksForwardAndBackward},
+		{"GetAllByCursorBreaksTiesById", testGetAllByCursorBreaksTiesById},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, newRepository(t))
+		})
+	}
+}
+
+func testSaveAndGetById(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	order := saveOrder(t, ctx, repository, "order-1", "first", time.Now())
+
+	loaded, err := repository.GetById(ctx, order.Id)
+	if err != nil {
+		t.Fatalf("GetById failed: %v", err)
+	}
+	if loaded.Id != order.Id || loaded.Name != order.Name || loaded.Version != order.Version {
+		t.Errorf("GetById returned %+v, expected %+v", loaded, order)
+	}
+	if !loaded.Created.Equal(order.Created.Truncate(time.Millisecond)) {
+		t.Errorf("GetById returned created %s, expected %s", loaded.Created, order.Created)
+	}
+	if events := loaded.Events(); len(events) != 0 {
+		t.Errorf("GetById returned order with recorded events %v, expected none", events)
+	}
+}
+
+func testSaveKeepsItemsAndTotals(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	items := []domain.LineItem{
+		domain.NewLineItem("sku-1", "first", 2, domain.NewMoney(1250, "EUR")),
+		domain.NewLineItem("sku-2", "second", 1, domain.NewMoney(999, "EUR")),
+	}
+	order, err := domain.CreateOrder(ctx, "order-1", "priced", items, 2100, domain.NewMoney(499, "EUR"))
+	if err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+	if err := repository.Save(ctx, order); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := repository.GetById(ctx, order.Id)
+	if err != nil {
+		t.Fatalf("GetById failed: %v", err)
+	}
+	if fmt.Sprint(loaded.Items) != fmt.Sprint(order.Items) {
+		t.Errorf("GetById returned items %v, expected %v", loaded.Items, order.Items)
+	}
+	expected := []domain.Money{
+		domain.NewMoney(3499, "EUR"), domain.NewMoney(499, "EUR"), domain.NewMoney(630, "EUR"), domain.NewMoney(3630, "EUR"),
+	}
+	actual := []domain.Money{loaded.Subtotal, loaded.Discount, loaded.Tax, loaded.Total}
+	if fmt.Sprint(actual) != fmt.Sprint(expected) || loaded.Currency != "EUR" || loaded.TaxRate != 2100 {
+		t.Errorf("GetById returned subtotal, discount, tax and total %v in %s, expected %v in EUR", actual, loaded.Currency, expected)
+	}
+}
+
+func testGetByIdNotFound(t *testing.T, repository domain.OrderRepository) {
+	_, err := repository.GetById(logging.NewInitialContext(), "missing")
+	expectErrorCode(t, err, apperrors.ENTITY_NOT_FOUND)
+}
+
+func testSaveIncrementsVersion(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	order := saveOrder(t, ctx, repository, "order-1", "first", time.Now())
+	if order.Version != 1 {
+		t.Fatalf("Version after insert is %d, expected 1", order.Version)
+	}
+
+	order.Name = "renamed"
+	if err := repository.Save(ctx, order); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if order.Version != 2 {
+		t.Fatalf("Version after update is %d, expected 2", order.Version)
+	}
+
+	loaded, err := repository.GetById(ctx, order.Id)
+	if err != nil {
+		t.Fatalf("GetById failed: %v", err)
+	}
+	if loaded.Version != 2 || loaded.Name != "renamed" {
+		t.Errorf("GetById returned %+v, expected version 2 named renamed", loaded)
+	}
+}
+
+func testSaveRejectsStaleVersion(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	saveOrder(t, ctx, repository, "order-1", "first", time.Now())
+
+	first, _ := repository.GetById(ctx, "order-1")
+	second, _ := repository.GetById(ctx, "order-1")
+	if err := repository.Save(ctx, first); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err := repository.Save(ctx, second)
+	expectErrorCode(t, err, apperrors.CONCURRENT_MODIFICATION)
+	if second.Version != 1 {
+		t.Errorf("Version after rejected save is %d, expected it to stay 1", second.Version)
+	}
+}
+
+func testSaveRejectsExistingIdForNewOrder(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	saveOrder(t, ctx, repository, "order-1", "first", time.Now())
+
+	duplicate, _ := domain.CreateOrder(ctx, "order-1", "duplicate", testItems(), 0, domain.Money{})
+	err := repository.Save(ctx, duplicate)
+	expectErrorCode(t, err, apperrors.ENTITY_ALREADY_EXIST)
+	if duplicate.Version != 0 {
+		t.Errorf("Version after rejected save is %d, expected it to stay 0", duplicate.Version)
+	}
+}
+
+func testSaveRejectsUpdateOfMissingOrder(t *testing.T, repository domain.OrderRepository) {
+	order, _ := domain.CreateOrder(logging.NewInitialContext(), "order-1", "first", testItems(), 0, domain.Money{})
+	order.Version = 3
+	err := repository.Save(logging.NewInitialContext(), order)
+	expectErrorCode(t, err, apperrors.CONCURRENT_MODIFICATION)
+}
+
+func testGetAllFiltersByIdAndName(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	now := time.Now()
+	saveOrder(t, ctx, repository, "a-1", "apple", now)
+	saveOrder(t, ctx, repository, "a-2", "apricot", now)
+	saveOrder(t, ctx, repository, "b-1", "Banana", now)
+	page := pageFilter(10, 1, "_id", common.SortAsc)
+
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Id: match("a-1", common.MatchExact)}, page), "a-1")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Id: match("a-", common.MatchPrefix)}, page), "a-1", "a-2")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Name: match("an", common.MatchContains)}, page), "b-1")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Name: match("banana", common.MatchIgnoreCase)}, page), "b-1")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Name: match("banana", common.MatchExact)}, page))
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Id: match("a-", common.MatchPrefix), Name: match("ri", common.MatchContains)}, page), "a-2")
+}
+
+func testGetAllTreatsFilterValuesLiterally(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	now := time.Now()
+	saveOrder(t, ctx, repository, "o-1", "a.c", now)
+	saveOrder(t, ctx, repository, "o-2", "abc", now)
+	page := pageFilter(10, 1, "_id", common.SortAsc)
+
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Name: match("a.", common.MatchPrefix)}, page), "o-1")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Name: match(".*", common.MatchContains)}, page))
+}
+
+func testGetAllFiltersByCreatedRange(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	saveOrder(t, ctx, repository, "o-1", "one", base)
+	saveOrder(t, ctx, repository, "o-2", "two", base.Add(time.Hour))
+	saveOrder(t, ctx, repository, "o-3", "three", base.Add(2*time.Hour))
+
+	from := base.Add(time.Hour)
+	to := base.Add(time.Hour)
+	page := pageFilter(10, 1, "_id", common.SortAsc)
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{CreatedFrom: &from}, page), "o-2", "o-3")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{CreatedTo: &to}, page), "o-1", "o-2")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{CreatedFrom: &from, CreatedTo: &to}, page), "o-2")
+}
+
+func testGetAllFiltersByStatus(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	now := time.Now()
+	saveOrder(t, ctx, repository, "o-1", "pending", now)
+	confirmed := saveOrder(t, ctx, repository, "o-2", "confirmed", now)
+	cancelled := saveOrder(t, ctx, repository, "o-3", "cancelled", now)
+	saveTransition(t, ctx, repository, confirmed, confirmed.Confirm)
+	saveTransition(t, ctx, repository, cancelled, cancelled.Cancel)
+	page := pageFilter(10, 1, "_id", common.SortAsc)
+
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Status: []domain.OrderStatus{domain.OrderStatusPending}}, page), "o-1")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Status: []domain.OrderStatus{domain.OrderStatusConfirmed, domain.OrderStatusCancelled}}, page), "o-2", "o-3")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{Status: []domain.OrderStatus{domain.OrderStatusPaid}}, page))
+
+	loaded, err := repository.GetById(ctx, "o-3")
+	if err != nil {
+		t.Fatalf("GetById failed: %v", err)
+	}
+	if loaded.Status != domain.OrderStatusCancelled {
+		t.Errorf("GetById returned status %s, expected %s", loaded.Status, domain.OrderStatusCancelled)
+	}
+}
+
+func testGetAllSortsAndPages(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	now := time.Now()
+	for i, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		saveOrder(t, ctx, repository, fmt.Sprintf("o-%d", i), name, now)
+	}
+
+	result := getAll(t, ctx, repository, &domain.OrderFilter{}, pageFilter(2, 1, "name", common.SortAsc))
+	expectIds(t, result, "o-1", "o-4")
+	if pagination := result.Pagination; pagination.Total == nil || *pagination.Total != 5 ||
+		pagination.TotalPage == nil || *pagination.TotalPage != 3 || pagination.Number != 1 {
+		t.Errorf("Unexpected pagination %+v", result.Pagination)
+	}
+
+	withoutTotal := pageFilter(2, 2, "name", common.SortAsc)
+	withoutTotal.IncludeTotal = false
+	result = getAll(t, ctx, repository, &domain.OrderFilter{}, withoutTotal)
+	expectIds(t, result, "o-3", "o-0")
+	if result.Pagination.Total != nil || result.Pagination.TotalPage != nil || result.Pagination.Number != 2 {
+		t.Errorf("Unexpected pagination without total %+v", result.Pagination)
+	}
+
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{}, pageFilter(2, 3, "name", common.SortAsc)), "o-2")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{}, pageFilter(2, 1, "name", common.SortDesc)), "o-2", "o-0")
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{}, pageFilter(2, 4, "name", common.SortAsc)))
+}
+
+func testGetAllSortsByMultipleFields(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	saveOrder(t, ctx, repository, "o-1", "bravo", base)
+	saveOrder(t, ctx, repository, "o-2", "alpha", base.Add(time.Hour))
+	saveOrder(t, ctx, repository, "o-3", "alpha", base)
+	saveOrder(t, ctx, repository, "o-4", "charlie", base.Add(time.Hour))
+
+	byCreatedDescNameAsc := sortedPageFilter(10, 1,
+		common.SortOrder{Field: "created", Type: common.SortDesc},
+		common.SortOrder{Field: "name", Type: common.SortAsc})
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{}, byCreatedDescNameAsc), "o-2", "o-4", "o-3", "o-1")
+
+	byNameAscCreatedDesc := sortedPageFilter(10, 1,
+		common.SortOrder{Field: "name", Type: common.SortAsc},
+		common.SortOrder{Field: "created", Type: common.SortDesc})
+	expectIds(t, getAll(t, ctx, repository, &domain.OrderFilter{}, byNameAscCreatedDesc), "o-2", "o-3", "o-1", "o-4")
+}
+
+func testGetAllByCursorWalksForwardAndBackward(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	now := time.Now()
+	for i, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		saveOrder(t, ctx, repository, fmt.Sprintf("o-%d", i), name, now)
+	}
+	filter := &common.CursorPageFilter{PageSize: 2, Sort: []common.SortOrder{{Field: "name", Type: common.SortAsc}}}
+
+	first := getAllByCursor(t, ctx, repository, filter)
+	expectCursorIds(t, first, "o-1", "o-4")
+	if first.NextCursor == nil || first.PrevCursor != nil {
+		t.Fatalf("First page has next %v and prev %v, expected only next", first.NextCursor, first.PrevCursor)
+	}
+
+	second := getAllByCursor(t, ctx, repository, withCursor(t, filter, first.NextCursor))
+	expectCursorIds(t, second, "o-3", "o-0")
+
+	third := getAllByCursor(t, ctx, repository, withCursor(t, filter, second.NextCursor))
+	expectCursorIds(t, third, "o-2")
+	if third.NextCursor != nil || third.PrevCursor == nil {
+		t.Fatalf("Last page has next %v and prev %v, expected only prev", third.NextCursor, third.PrevCursor)
+	}
+
+	back := getAllByCursor(t, ctx, repository, withCursor(t, filter, third.PrevCursor))
+	expectCursorIds(t, back, "o-3", "o-0")
+
+	backToFirst := getAllByCursor(t, ctx, repository, withCursor(t, filter, back.PrevCursor))
+	expectCursorIds(t, backToFirst, "o-1", "o-4")
+	if backToFirst.PrevCursor != nil || backToFirst.NextCursor == nil {
+		t.Errorf("First page reached backward has next %v and prev %v, expected only next", backToFirst.NextCursor, backToFirst.PrevCursor)
+	}
+}
+
+func testGetAllByCursorBreaksTiesById(t *testing.T, repository domain.OrderRepository) {
+	ctx := logging.NewInitialContext()
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"o-3", "o-1", "o-4", "o-2"} {
+		saveOrder(t, ctx, repository, id, "same", created)
+	}
+	filter := &common.CursorPageFilter{PageSize: 3, Sort: []common.SortOrder{{Field: "created", Type: common.SortDesc}}}
+
+	first := getAllByCursor(t, ctx, repository, filter)
+	expectCursorIds(t, first, "o-4", "o-3", "o-2")
+
+	second := getAllByCursor(t, ctx, repository, withCursor(t, filter, first.NextCursor))
+	expectCursorIds(t, second, "o-1")
+}
+
+func saveOrder(t *testing.T, ctx context.Context, repository domain.OrderRepository, id string, name string, created time.Time) *domain.Order {
+	t.Helper()
+	order, err := domain.CreateOrder(ctx, id, name, testItems(), 0, domain.Money{})
+	if err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+	order.Created = created
+	if err := repository.Save(ctx, order); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return order
+}
+
+func saveTransition(t *testing.T, ctx context.Context, repository domain.OrderRepository, order *domain.Order, transition func() error) {
+	t.Helper()
+	if err := transition(); err != nil {
+		t.Fatalf("Transition of order %s failed: %v", order.Id, err)
+	}
+	if err := repository.Save(ctx, order); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+}
+
+func testItems() []domain.LineItem {
+	return []domain.LineItem{domain.NewLineItem("sku-1", "item", 1, domain.NewMoney(100, "EUR"))}
+}
+
+func getAll(t *testing.T, ctx context.Context, repository domain.OrderRepository, filter *domain.OrderFilter, page *common.PageFilter) *common.Paginated[domain.Order] {
+	t.Helper()
+	result, err := repository.GetAll(ctx, filter, page)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	return result
+}
+
+func getAllByCursor(t *testing.T, ctx context.Context, repository domain.OrderRepository, page *common.CursorPageFilter) *common.CursorPaginated[domain.Order] {
+	t.Helper()
+	result, err := repository.GetAllByCursor(ctx, &domain.OrderFilter{}, page)
+	if err != nil {
+		t.Fatalf("GetAllByCursor failed: %v", err)
+	}
+	return result
+}
+
+// withCursor continues the first page filter with the cursor, after it round trips through its token
+func withCursor(t *testing.T, filter *common.CursorPageFilter, cursor *common.Cursor) *common.CursorPageFilter {
+	t.Helper()
+	if cursor == nil {
+		t.Fatal("Expected a cursor to continue with")
+	}
+	codec := common.NewCursorCodec([]byte("contract"))
+	token, err := codec.Encode(cursor)
+	if err != nil {
+		t.Fatalf("Encoding cursor failed: %v", err)
+	}
+	decoded, err := codec.Decode(token)
+	if err != nil {
+		t.Fatalf("Decoding cursor failed: %v", err)
+	}
+	return &common.CursorPageFilter{PageSize: filter.PageSize, Sort: decoded.Sort, Cursor: decoded}
+}
+
+func match(value string, mode common.MatchMode) *common.StringFilter {
+	return &common.StringFilter{Value: value, Mode: mode}
+}
+
+func pageFilter(pageSize int64, page int64, sortField string, sortType common.SortType) *common.PageFilter {
+	return sortedPageFilter(pageSize, page, common.SortOrder{Field: sortField, Type: sortType})
+}
+
+func sortedPageFilter(pageSize int64, page int64, sortOrders ...common.SortOrder) *common.PageFilter {
+	return &common.PageFilter{
+		PageSize:     pageSize,
+		Page:         page,
+		Sort:         sortOrders,
+		IncludeTotal: true,
+	}
+}
+
+func expectIds(t *testing.T, result *common.Paginated[domain.Order], ids ...string) {
+	t.Helper()
+	actual := make([]string, 0, len(result.Data))
+	for _, order := range result.Data {
+		actual = append(actual, order.Id)
+	}
+	if fmt.Sprint(actual) != fmt.Sprint(ids) {
+		t.Errorf("GetAll returned %v, expected %v", actual, ids)
+	}
+}
+
+func expectCursorIds(t *testing.T, result *common.CursorPaginated[domain.Order], ids ...string) {
+	t.Helper()
+	expectIds(t, &common.Paginated[domain.Order]{Data: result.Data}, ids...)
+}
+
+func expectErrorCode(t *testing.T, err error, errorCode string) {
+	t.Helper()
+	if !apperrors.Is(err, errorCode) {
+		t.Errorf("Expected error with code %s, got %v", errorCode, err)
+	}
+}
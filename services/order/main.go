@@ -3,142 +3,268 @@ package main
 import (
 	"common"
 	apperrors "common/errors"
+	"common/logging"
+	"common/messaging"
+	"common/outbox"
+	commonusecase "common/usecase"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"github.com/apex/log"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"net/http"
 	"order/application"
 	"order/application/usecase"
 	"order/domain"
 	"order/infrastructure"
-	"os"
-	"time"
 )
 
 var orderApplication *application.OrderApplication
+var cursorCodec *common.CursorCodec
+var outboxRelay *outbox.Relay
+var mongoOrderRepository *infrastructure.OrderRepositoryImpl
+var outboxStore *outbox.MongoStore
+var config Config
 
-func init() {
+// setup wires the service at cold start, it is not an init function so that tests of this package
+// do not need a configured environment
+func setup() {
+	logging.Init()
 
-	// Load environment variables for MongoDB configuration
-	mongoUrl := os.Getenv("MONGO_URL")
-	mongoDatabaseName := os.Getenv("MONGO_DB_NAME")
-	mongoDatabaseUsername := os.Getenv("DB_USERNAME")
-	mongoDatabasePassword := os.Getenv("DB_PASSWORD")
-	//secretARN := os.Getenv("MONGO_SECRET_ARN")
-
-	fullMongoURI := fmt.Sprintf("mongodb://%s:%s@%s/sample-database?tls=true&replicaSet=rs0&readpreference=secondaryPreferred", mongoDatabaseUsername, mongoDatabasePassword, mongoUrl)
-
-	log.Infof("Connecto to mongo: %s", fullMongoURI)
-	mongoConnectionTimeout := common.GetEnvDuration("MONGO_CONNECTION_TIMEOUT", 10*time.Second)
-
-	// Set MongoDB connection timeout
-	mongoCtx, cancel := context.WithTimeout(context.Background(), mongoConnectionTimeout)
-	defer cancel()
+	// Load environment variables
+	if err := common.LoadConfig(&config); err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
 
-	// Connect to MongoDB
-	mongoClient, err := mongo.Connect(mongoCtx, options.Client().ApplyURI(fullMongoURI).SetTLSConfig(nil).SetRetryWrites(false))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	if err := common.ConfigureErrorResponses(common.ErrorResponseOptions{Format: common.ErrorFormat(config.ErrorFormat)}); err != nil {
+		log.Fatalf("Failed to configure error responses: %v", err)
 	}
 
-	//Ping MongoDB to check if the connection was successful
-	if err := mongoClient.Ping(mongoCtx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+	// brokerPublisher delivers events, directly or through the outbox relay
+	brokerPublisher := newBrokerPublisher(config)
+
+	var orderRepository domain.OrderRepository
+	var transactions common.TransactionRunner = common.NoTransaction{}
+	publisher := brokerPublisher
+	switch config.OrderRepository {
+	case "memory":
+		log.Warn("Using in-memory order repository, orders are lost when the function instance is recycled")
+		orderRepository = infrastructure.NewInMemoryOrderRepository()
+	case "mongo":
+		mongoClient := connectMongo(config)
+		mongoOrderRepository = infrastructure.NewOrderRepository(mongoClient, config.MongoDatabaseName)
+		orderRepository = mongoOrderRepository
+		if config.OutboxEnabled {
+			outboxStore = outbox.NewMongoStore(mongoClient, config.MongoDatabaseName)
+			transactions = common.NewMongoTransactionRunner(mongoClient)
+			publisher = outbox.NewPublisher(outboxStore)
+			outboxRelay = outbox.NewRelay(outboxStore, brokerPublisher, outbox.RelayOptions{
+				BatchSize:      config.OutboxBatchSize,
+				MaxAttempts:    config.OutboxMaxAttempts,
+				InitialBackoff: config.OutboxInitialBackoff,
+				MaxBackoff:     config.OutboxMaxBackoff,
+				Lease:          config.OutboxLease,
+			})
+			if config.RunMode == RunModeOutboxRelay || config.RunMode == RunModeOutboxRelayLoop {
+				ensureOutboxCollection(config, outboxStore)
+			}
+		}
+	default:
+		log.Fatalf("Unsupported ORDER_REPOSITORY '%s', expected mongo or memory", config.OrderRepository)
 	}
 
-	// Log successful connection
-	log.Infof("Successfully connected to MongoDB at %s", mongoUrl)
+	orderApplication = newOrderApplication(config, orderRepository, publisher, transactions)
 
-	orderRepository := infrastructure.NewOrderRepository(mongoClient, mongoDatabaseName)
-	getOrderQueryHandler := usecase.NewGetOrderQueryHandler(orderRepository)
-	getOrderAllOrdersQueryHandler := usecase.NewGetAllOrdersQueryHandler(orderRepository)
-	createOrderCommandHandler := usecase.NewCreateOrderCommandHandler(orderRepository)
+	if config.RunMode == RunModeApi {
+		key, err := cursorSigningKey(config)
+		if err != nil {
+			log.Fatalf("Failed to configure cursor pagination: %v", err)
+		}
+		cursorCodec = common.NewCursorCodec(key)
+	}
+}
 
-	orderApplication = application.NewOrderApplication(
-		getOrderQueryHandler,
-		getOrderAllOrdersQueryHandler,
-		createOrderCommandHandler,
+func newOrderApplication(config Config, orderRepository domain.OrderRepository, publisher messaging.Publisher, transactions common.TransactionRunner) *application.OrderApplication {
+	return application.NewOrderApplication(
+		newCommandPipeline(config),
+		usecase.NewGetOrderQueryHandler(orderRepository),
+		usecase.NewGetAllOrdersQueryHandler(orderRepository),
+		usecase.NewGetAllOrdersByCursorQueryHandler(orderRepository),
+		usecase.NewCreateOrderCommandHandler(orderRepository, publisher, transactions),
+		usecase.NewChangeOrderStatusCommandHandler(orderRepository, publisher, transactions),
 	)
 }
 
-type MongoCredentials struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+// newCommandPipeline decorates every handler with logging, metrics, validation, authorization, a
+// timeout and retries of commands which lost an optimistic locking race. Every command is allowed:
+// API callers are authorized by API Gateway and payment events come from the service's own queue.
+func newCommandPipeline(config Config) *commonusecase.Pipeline {
+	return commonusecase.NewPipeline(
+		commonusecase.Logging(),
+		commonusecase.Metrics(commonusecase.LogMetricsRecorder{}),
+		commonusecase.Validation(),
+		commonusecase.Authorization(commonusecase.AllowAll),
+		commonusecase.Timeout(config.CommandTimeout),
+		commonusecase.Retry(commonusecase.DefaultRetryOptions()),
+	)
 }
 
-func CreateOrderHandler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	fmt.Println(fmt.Sprintf("CreateOrderHandler: %s", request.HTTPMethod))
-	orderId, isSpecificOrder := request.PathParameters["orderId"]
-
-	switch request.HTTPMethod {
-	case "POST":
-		// Handle creating a new order
-		return createOrder(ctx, request)
-	case "GET":
-		if isSpecificOrder {
-			// Return specific order by ID
-			return getOrder(ctx, orderId, request)
-		} else {
-			// Return all orders
-			return getAllOrders(ctx, request)
-		}
-	default:
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusMethodNotAllowed,
-			Body:       "Unsupported HTTP method",
-		}, nil
+func newRouter() *common.Router {
+	router := common.NewRouter()
+	router.Use(common.DefaultMiddlewares()...)
+	router.Get("/orders", getAllOrders)
+	router.Post("/orders", createOrder)
+	router.Get("/orders/{orderId}", getOrder)
+	for _, action := range domain.OrderActions {
+		router.Post(orderActionPath(action), changeOrderStatus(action))
 	}
+	return router
 }
 
 // Retrieve an order (GET /orders/{orderID})
-func getOrder(ctx context.Context, orderID string, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func getOrder(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 
-	orderResult, err := orderApplication.GetOrderQueryHandler.Execute(ctx, usecase.GetOrderQuery{Id: orderID})
+	orderResult, err := orderApplication.GetOrderQueryHandler.Handle(ctx, usecase.GetOrderQuery{Id: request.PathParameters["orderId"]})
 
 	if err != nil {
-		return common.SerializeError(err)
+		return events.APIGatewayProxyResponse{}, err
+	}
+	if common.IfNoneMatch(request, orderResult.Version) {
+		return common.NotModified(orderResult.Version), nil
 	}
-	return common.SerializeResponse(http.StatusOK, orderResult)
+	response, err := common.SerializeResponse(http.StatusOK, orderResult)
+	return common.SetETag(response, orderResult.Version), err
 }
 
-// Retrieve an order (GET /orders/{orderID})
+// Retrieve all orders (GET /orders). Keyset pagination is used when the cursor query parameter
+// is given or pagination=cursor is requested, page number pagination otherwise.
 func getAllOrders(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 
-	pageFilter := common.ParsePageFilter(request.QueryStringParameters)
+	orderFilter, err := domain.ParseOrderFilter(common.QueryValues(request))
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	if request.QueryStringParameters["cursor"] != "" || request.QueryStringParameters["pagination"] == "cursor" {
+		return getAllOrdersByCursor(ctx, request, orderFilter)
+	}
 
-	result, err := orderApplication.GetAllOrdersQueryHandler.Execute(ctx, usecase.GetAllOrdersQuery{
-		Filter: &domain.OrderFilter{},
+	pageFilter, err := common.ParsePageFilter(request.QueryStringParameters, domain.OrderPageFilterOptions)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	result, err := orderApplication.GetAllOrdersQueryHandler.Handle(ctx, usecase.GetAllOrdersQuery{
+		Filter: orderFilter,
 		Page:   pageFilter,
 	})
 	if err != nil {
-		log.WithError(err).Warn("Request failed")
-		return common.SerializeError(err)
+		return events.APIGatewayProxyResponse{}, err
 	}
 	return common.SerializeResponse(http.StatusOK, result)
 }
 
+func getAllOrdersByCursor(ctx context.Context, request events.APIGatewayProxyRequest, orderFilter *domain.OrderFilter) (events.APIGatewayProxyResponse, error) {
+	pageFilter, err := common.ParseCursorPageFilter(request.QueryStringParameters, domain.OrderPageFilterOptions, cursorCodec)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	result, err := orderApplication.GetAllOrdersByCursorQueryHandler.Handle(ctx, usecase.GetAllOrdersByCursorQuery{
+		Filter: orderFilter,
+		Page:   pageFilter,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	if err := common.EncodeCursorPage(cursorCodec, result); err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	return common.SerializeResponse(http.StatusOK, result)
+}
+
+// Create an order (POST /orders)
 func createOrder(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	var createOrderCommand usecase.CreateOrderCommand
 	err := json.Unmarshal([]byte(request.Body), &createOrderCommand)
 	if err != nil {
-		return common.SerializeError(apperrors.InvalidRequest("Failed to parse request", err))
+		return events.APIGatewayProxyResponse{}, apperrors.InvalidRequest("Failed to parse request", err)
 	}
 
-	orderResult, err := orderApplication.CreateOrderCommandHandler.Execute(ctx, createOrderCommand)
+	orderResult, err := orderApplication.CreateOrderCommandHandler.Handle(ctx, createOrderCommand)
 
 	if err != nil {
-		log.WithError(err).Warn("Request failed")
-		return common.SerializeError(err)
+		return events.APIGatewayProxyResponse{}, err
 	}
 
-	return common.SerializeResponse(http.StatusCreated, orderResult)
+	response, err := common.SerializeResponse(http.StatusCreated, orderResult)
+	return common.SetETag(response, orderResult.Version), err
+}
+
+// orderActionPath returns the path of an order status change, e.g. /orders/{orderId}/confirm
+func orderActionPath(action domain.OrderAction) string {
+	return "/orders/{orderId}/" + string(action)
+}
+
+// Change the status of an order (POST /orders/{orderId}/{action}). The If-Match header may require
+// the order to still have the version the client has seen.
+func changeOrderStatus(action domain.OrderAction) common.HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		expectedVersion, err := common.IfMatchVersion(request)
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+
+		orderResult, err := orderApplication.ChangeOrderStatusCommandHandler.Handle(ctx, usecase.ChangeOrderStatusCommand{
+			Id:              request.PathParameters["orderId"],
+			Action:          action,
+			ExpectedVersion: expectedVersion,
+		})
+
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+
+		response, err := common.SerializeResponse(http.StatusOK, orderResult)
+		return common.SetETag(response, orderResult.Version), err
+	}
+}
+
+// cursorSigningKey returns the configured key. Only the memory repository, which is local to the
+// function instance anyway, falls back to a random key.
+func cursorSigningKey(config Config) ([]byte, error) {
+	if config.CursorSigningKey != "" {
+		return []byte(config.CursorSigningKey), nil
+	}
+	if config.OrderRepository != "memory" {
+		return nil, fmt.Errorf("CURSOR_SIGNING_KEY is required when ORDER_REPOSITORY is %s", config.OrderRepository)
+	}
+	log.Warn("CURSOR_SIGNING_KEY is not set, continuation tokens are valid only within this function instance")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate cursor signing key: %w", err)
+	}
+	return key, nil
 }
 
 func main() {
-	lambda.Start(CreateOrderHandler)
+	setup()
+
+	switch config.RunMode {
+	case RunModeApi:
+		lambda.Start(newRouter().Handle)
+	case RunModeOutboxRelay:
+		requireOutboxRelay()
+		lambda.Start(relayOutbox)
+	case RunModeOutboxRelayLoop:
+		requireOutboxRelay()
+		runOutboxRelayLoop()
+	case RunModePaymentConsumer:
+		lambda.Start(newPaymentConsumer().Handle)
+	case RunModeMigrate:
+		migrate()
+	default:
+		log.Fatalf("Unsupported RUN_MODE '%s', expected %s, %s, %s, %s or %s", config.RunMode, RunModeApi, RunModeOutboxRelay, RunModeOutboxRelayLoop, RunModePaymentConsumer, RunModeMigrate)
+	}
 }
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCursorSigningKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		wantKey    string
+		wantRandom bool
+		wantErr    bool
+	}{
+		{name: "configured", config: Config{OrderRepository: "mongo", CursorSigningKey: "key"}, wantKey: "key"},
+		{name: "required for mongo", config: Config{OrderRepository: "mongo"}, wantErr: true},
+		{name: "random for memory", config: Config{OrderRepository: "memory"}, wantRandom: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := cursorSigningKey(test.config)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("cursorSigningKey() error = %v, want error %v", err, test.wantErr)
+			}
+			if test.wantRandom && len(key) != 32 {
+				t.Errorf("cursorSigningKey() = %d random bytes, want 32", len(key))
+			}
+			if test.wantKey != "" && string(key) != test.wantKey {
+				t.Errorf("cursorSigningKey() = %s, want %s", key, test.wantKey)
+			}
+		})
+	}
+}
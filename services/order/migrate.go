@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"github.com/apex/log"
+)
+
+// Prepare the Mongo collections of a new release (RUN_MODE=migrate). It has to run before the API
+// of a release is used: orders are saved with their outbox entries in a transaction, which can not
+// create the outbox collection on MongoDB versions before 4.4.
+func migrate() {
+	if mongoOrderRepository == nil {
+		log.Fatalf("Migration requires ORDER_REPOSITORY=mongo")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.MongoConnectionTimeout)
+	defer cancel()
+
+	if outboxStore != nil {
+		ensureOutboxCollection(config, outboxStore)
+	}
+
+	backfilled, err := mongoOrderRepository.BackfillStatus(ctx)
+	if err != nil {
+		log.Fatalf("Failed to backfill order status: %v", err)
+	}
+	log.Infof("Migration finished, backfilled the status of %d orders", backfilled)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"common/logging"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
+	"net/url"
+)
+
+// maxMongoAuthAttempts bounds the connection attempts with credentials read again after an
+// authentication failure, e.g. when the secret was rotated after it was cached
+const maxMongoAuthAttempts = 3
+
+func connectMongo(config Config) *mongo.Client {
+	if config.MongoUrl == "" || config.MongoDatabaseName == "" {
+		log.Fatalf("MONGO_URL and MONGO_DB_NAME must be set when ORDER_REPOSITORY is mongo")
+	}
+
+	// Set MongoDB connection timeout
+	mongoCtx, cancel := context.WithTimeout(context.Background(), config.MongoConnectionTimeout)
+	defer cancel()
+
+	credentials, err := newMongoCredentialsSource(mongoCtx, config)
+	if err != nil {
+		log.Fatalf("Failed to resolve MongoDB credentials: %v", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		mongoClient, err := dialMongo(mongoCtx, config, credentials)
+		if err == nil {
+			// Log successful connection
+			log.Infof("Successfully connected to MongoDB at %s", config.MongoUrl)
+			return mongoClient
+		}
+		if attempt < maxMongoAuthAttempts && isMongoAuthenticationError(err) && credentials.Invalidate() {
+			log.WithError(err).Warn("MongoDB authentication failed, reading the credentials again")
+			continue
+		}
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+}
+
+// dialMongo connects with the current credentials and pings MongoDB, which authenticates them
+func dialMongo(ctx context.Context, config Config, source *mongoCredentialsSource) (*mongo.Client, error) {
+	credentials, err := source.Resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve MongoDB credentials: %w", err)
+	}
+
+	fullMongoURI := mongoURI(config.MongoUrl, credentials)
+
+	log.Infof("Connecting to mongo: %s", logging.RedactURI(fullMongoURI))
+
+	// Connect to MongoDB
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(fullMongoURI).SetTLSConfig(nil).SetRetryWrites(false))
+	if err != nil {
+		return nil, err
+	}
+
+	//Ping MongoDB to check if the connection was successful
+	if err := mongoClient.Ping(ctx, nil); err != nil {
+		_ = mongoClient.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	return mongoClient, nil
+}
+
+// mongoAuthenticationFailed is the code of the AuthenticationFailed server error
+const mongoAuthenticationFailed = 18
+
+// isMongoAuthenticationError reports whether err was caused by rejected credentials
+func isMongoAuthenticationError(err error) bool {
+	var authError *auth.Error
+	if errors.As(err, &authError) {
+		return true
+	}
+	var commandError mongo.CommandError
+	return errors.As(err, &commandError) && commandError.Code == mongoAuthenticationFailed
+}
+
+// mongoURI builds the connection string, user and password are escaped as URI userinfo
+func mongoURI(hosts string, credentials *MongoCredentials) string {
+	uri := url.URL{
+		Scheme:   "mongodb",
+		User:     url.UserPassword(credentials.Username, credentials.Password),
+		Host:     hosts,
+		Path:     "/sample-database",
+		RawQuery: "tls=true&replicaSet=rs0&readpreference=secondaryPreferred",
+	}
+	return uri.String()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"testing"
+)
+
+func TestMongoURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    string
+		username string
+		password string
+	}{
+		{"plain", "host:27017", "user", "secret"},
+		{"space in password", "host:27017", "user", "my secret"},
+		{"reserved characters", "host:27017", "us@r", "p@ss:w/rd?#%+"},
+		{"several hosts", "a:27017,b:27017", "user", "secret"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uri := mongoURI(test.hosts, &MongoCredentials{Username: test.username, Password: test.password})
+			parsed, err := connstring.Parse(uri)
+			if err != nil {
+				t.Fatalf("connstring.Parse(%s) error = %v", uri, err)
+			}
+			if parsed.Username != test.username || parsed.Password != test.password {
+				t.Errorf("credentials %q:%q, want %q:%q", parsed.Username, parsed.Password, test.username, test.password)
+			}
+			if parsed.Database != "sample-database" || parsed.ReplicaSet != "rs0" {
+				t.Errorf("database %s and replica set %s", parsed.Database, parsed.ReplicaSet)
+			}
+		})
+	}
+}
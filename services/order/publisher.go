@@ -0,0 +1,39 @@
+package main
+
+import (
+	"common/messaging"
+	"context"
+	"github.com/apex/log"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+func newBrokerPublisher(config Config) messaging.Publisher {
+	switch config.EventPublisher {
+	case "log":
+		return messaging.NewLogPublisher()
+	case "memory":
+		return messaging.NewFakePublisher(config.EventSource)
+	case "eventbridge", "sns", "sqs":
+	default:
+		log.Fatalf("Unsupported EVENT_PUBLISHER '%s', expected eventbridge, sns, sqs, log or memory", config.EventPublisher)
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load AWS configuration: %v", err)
+	}
+	switch config.EventPublisher {
+	case "sns":
+		if config.EventTopicArn == "" {
+			log.Fatalf("EVENT_TOPIC_ARN must be set when EVENT_PUBLISHER is sns")
+		}
+		return messaging.NewSnsPublisher(awsConfig, config.EventTopicArn, config.EventSource)
+	case "sqs":
+		if config.EventQueueUrl == "" {
+			log.Fatalf("EVENT_QUEUE_URL must be set when EVENT_PUBLISHER is sqs")
+		}
+		return messaging.NewSqsPublisher(awsConfig, config.EventQueueUrl, config.EventSource)
+	default:
+		return messaging.NewEventBridgePublisher(awsConfig, config.EventBusName, config.EventSource)
+	}
+}
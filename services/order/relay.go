@@ -0,0 +1,48 @@
+package main
+
+import (
+	"common/logging"
+	"common/outbox"
+	"context"
+	"github.com/apex/log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Publish pending outbox entries once (RUN_MODE=outbox-relay), e.g. on an EventBridge schedule
+func relayOutbox(ctx context.Context) (outbox.RelayResult, error) {
+	ctx = logging.AddTraceToContext(ctx, "")
+	result, err := outboxRelay.RunOnce(ctx)
+	if err != nil {
+		logging.Log(ctx, "OutboxRelay").WithError(err).Error("Outbox relay run failed")
+		return result, err
+	}
+	logging.Log(ctx, "OutboxRelay").WithField("result", result).Info("Outbox relay run finished")
+	return result, nil
+}
+
+// Publish pending outbox entries until the process is interrupted (RUN_MODE=outbox-relay-loop)
+func runOutboxRelayLoop() {
+	ctx, stop := signal.NotifyContext(logging.NewInitialContext(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Infof("Running outbox relay every %s", config.OutboxRelayInterval)
+	if err := outboxRelay.Run(ctx, config.OutboxRelayInterval); err != nil && ctx.Err() == nil {
+		log.Fatalf("Outbox relay stopped: %v", err)
+	}
+}
+
+func requireOutboxRelay() {
+	if outboxRelay == nil {
+		log.Fatalf("Outbox relay requires ORDER_REPOSITORY=mongo and OUTBOX_ENABLED=true")
+	}
+}
+
+func ensureOutboxCollection(config Config, store *outbox.MongoStore) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.MongoConnectionTimeout)
+	defer cancel()
+	if err := store.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create outbox collection: %v", err)
+	}
+}
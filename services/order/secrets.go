@@ -0,0 +1,84 @@
+package main
+
+import (
+	"common/secrets"
+	"context"
+	"fmt"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+type MongoCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password" log:"redact"`
+}
+
+// newSecretsProvider returns the configured provider, cached for SECRETS_CACHE_TTL
+func newSecretsProvider(ctx context.Context, config Config) (*secrets.CachingProvider, error) {
+	var provider secrets.Provider
+	switch config.SecretsProvider {
+	case "secretsmanager", "ssm":
+		awsConfig, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+		}
+		if config.SecretsProvider == "ssm" {
+			provider = secrets.NewParameterStoreProvider(awsConfig)
+		} else {
+			provider = secrets.NewSecretsManagerProvider(awsConfig)
+		}
+	case "env":
+		provider = secrets.NewEnvProvider()
+	case "file":
+		provider = secrets.NewFileProvider(config.SecretsDirectory)
+	default:
+		return nil, fmt.Errorf("unsupported secrets provider '%s'", config.SecretsProvider)
+	}
+	return secrets.NewCachingProvider(provider, config.SecretsCacheTtl), nil
+}
+
+// mongoCredentialsSource resolves the Mongo credentials from DB_USERNAME and DB_PASSWORD or from the
+// secret MONGO_SECRET_ARN
+type mongoCredentialsSource struct {
+	config  Config
+	secrets *secrets.CachingProvider
+}
+
+func newMongoCredentialsSource(ctx context.Context, config Config) (*mongoCredentialsSource, error) {
+	source := &mongoCredentialsSource{config: config}
+	if config.MongoSecretArn == "" {
+		if config.MongoUsername == "" || config.MongoPassword == "" {
+			return nil, fmt.Errorf("either MONGO_SECRET_ARN or DB_USERNAME and DB_PASSWORD must be set")
+		}
+		return source, nil
+	}
+	provider, err := newSecretsProvider(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	source.secrets = provider
+	return source, nil
+}
+
+func (s *mongoCredentialsSource) Resolve(ctx context.Context) (*MongoCredentials, error) {
+	if s.secrets == nil {
+		return &MongoCredentials{
+			Username: s.config.MongoUsername,
+			Password: s.config.MongoPassword,
+		}, nil
+	}
+	var credentials MongoCredentials
+	if err := secrets.GetJSONSecret(ctx, s.secrets, s.config.MongoSecretArn, &credentials); err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+// Invalidate drops the cached secret after authentication with it failed, so that the next Resolve
+// reads rotated credentials. It reports whether credentials can change at all.
+func (s *mongoCredentialsSource) Invalidate() bool {
+	if s.secrets == nil {
+		return false
+	}
+	s.secrets.Invalidate(s.config.MongoSecretArn)
+	return true
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMongoCredentialsSource(t *testing.T) {
+	writeSecret := func(t *testing.T, directory string, password string) {
+		content := fmt.Sprintf(`{"username":"order","password":"%s"}`, password)
+		if err := os.WriteFile(filepath.Join(directory, "mongo"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		config          Config
+		secret          bool
+		wantErr         bool
+		wantPassword    string
+		wantInvalidated bool
+		// wantRotated is the password after the secret changed and the cache was invalidated
+		wantRotated string
+	}{
+		{name: "environment", config: Config{MongoUsername: "order", MongoPassword: "env"}, wantPassword: "env", wantRotated: "env"},
+		{name: "missing", config: Config{MongoUsername: "order"}, wantErr: true},
+		{name: "secret", config: Config{MongoSecretArn: "mongo", SecretsProvider: "file", SecretsCacheTtl: time.Hour}, secret: true,
+			wantPassword: "first", wantInvalidated: true, wantRotated: "second"},
+		{name: "unknown provider", config: Config{MongoSecretArn: "mongo", SecretsProvider: "vault"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			test.config.SecretsDirectory = t.TempDir()
+			if test.secret {
+				writeSecret(t, test.config.SecretsDirectory, "first")
+			}
+
+			source, err := newMongoCredentialsSource(ctx, test.config)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("newMongoCredentialsSource() error = %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			credentials, err := source.Resolve(ctx)
+			if err != nil || credentials.Password != test.wantPassword {
+				t.Fatalf("Resolve() = %v, %v, want password %s", credentials, err, test.wantPassword)
+			}
+
+			if test.secret {
+				writeSecret(t, test.config.SecretsDirectory, "second")
+				if credentials, _ := source.Resolve(ctx); credentials.Password != test.wantPassword {
+					t.Errorf("Resolve() before Invalidate() = %s, want cached %s", credentials.Password, test.wantPassword)
+				}
+			}
+			if invalidated := source.Invalidate(); invalidated != test.wantInvalidated {
+				t.Errorf("Invalidate() = %v, want %v", invalidated, test.wantInvalidated)
+			}
+			if credentials, _ := source.Resolve(ctx); credentials.Password != test.wantRotated {
+				t.Errorf("Resolve() after Invalidate() = %s, want %s", credentials.Password, test.wantRotated)
+			}
+		})
+	}
+}
+
+func TestIsMongoAuthenticationError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"auth error", fmt.Errorf("failed to ping MongoDB: %w", &auth.Error{}), true},
+		{"authentication failed command", mongo.CommandError{Code: mongoAuthenticationFailed, Message: "Authentication failed."}, true},
+		{"other command error", mongo.CommandError{Code: 13, Message: "Unauthorized"}, false},
+		{"network error", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isMongoAuthenticationError(test.err); got != test.want {
+				t.Errorf("isMongoAuthenticationError(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}